@@ -3,6 +3,7 @@ module github.com/ctison/gpm
 go 1.19
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/charmbracelet/bubbles v0.14.0
 	github.com/charmbracelet/bubbletea v0.22.1
 	github.com/charmbracelet/lipgloss v0.9.1
@@ -11,6 +12,8 @@ require (
 	github.com/hashicorp/go-getter/v2 v2.2.1
 	github.com/spf13/cobra v1.7.0
 	golang.org/x/exp v0.0.0-20220929160808-de9c53c655b9
+	golang.org/x/term v0.11.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -42,6 +45,5 @@ require (
 	github.com/ulikunitz/xz v0.5.10 // indirect
 	golang.org/x/crypto v0.12.0 // indirect
 	golang.org/x/sys v0.12.0 // indirect
-	golang.org/x/term v0.11.0 // indirect
 	golang.org/x/text v0.12.0 // indirect
 )
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/ctison/gpm/pkg/cmd"
 )
@@ -11,9 +14,17 @@ var (
 )
 
 func main() {
-	cmd := cmd.NewRootCommand()
-	cmd.Version = version
-	if err := cmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	rootCmd := cmd.NewRootCommand()
+	rootCmd.Version = version
+	err := rootCmd.ExecuteContext(ctx)
+	// Check whether gpm was interrupted before stop cancels ctx.
+	canceled := ctx.Err() != nil
+	stop()
+	if err != nil {
+		if canceled {
+			os.Exit(cmd.ExitCodeCanceled)
+		}
 		os.Exit(1)
 	}
 }
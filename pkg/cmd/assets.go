@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ctison/gpm/pkg/gpm"
+	"github.com/ctison/gpm/pkg/tui"
+	"github.com/spf13/cobra"
+)
+
+type AssetsCommand struct {
+	RootCommand *RootCommand
+	JSON        bool
+}
+
+type assetOutput struct {
+	Name          string `json:"name"`
+	Size          int    `json:"size"`
+	Downloads     int    `json:"downloads"`
+	ContentType   string `json:"content_type"`
+	URL           string `json:"url"`
+	MatchPlatform bool   `json:"match_platform"`
+}
+
+func NewCommandAssets(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Use = "assets [OWNER/]REPOSITORY[@TAG]"
+	cmd.Short = "List assets of a Github release (Defaults to the latest release)"
+	cmd.Args = cobra.ExactArgs(1)
+
+	assetsCommand := AssetsCommand{
+		RootCommand: rootCommand,
+	}
+
+	cmd.Flags().BoolVar(&assetsCommand.JSON, "json", false, "Output in JSON")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return assetsCommand.RunE(cmd, args)
+	}
+	return cmd
+}
+
+func (assetsCommand AssetsCommand) RunE(cmd *cobra.Command, args []string) error {
+	dep, err := assetsCommand.RootCommand.parseRepository(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+	release, err := assetsCommand.RootCommand.GPM.GetRelease(cmd.Context(), dep.Owner, dep.Repo, dep.ReleaseTag)
+	if err != nil {
+		return err
+	}
+	platform := gpm.CurrentPlatform()
+	outputs := make([]assetOutput, 0, len(release.Assets))
+	for _, asset := range release.Assets {
+		outputs = append(outputs, assetOutput{
+			Name:          asset.GetName(),
+			Size:          asset.GetSize(),
+			Downloads:     asset.GetDownloadCount(),
+			ContentType:   asset.GetContentType(),
+			URL:           asset.GetBrowserDownloadURL(),
+			MatchPlatform: gpm.MatchPlatform(asset.GetName(), platform),
+		})
+	}
+	if assetsCommand.JSON {
+		return printJSON(os.Stdout, outputs)
+	}
+	fmt.Printf("Assets of %s/%s@%s (* matches %s):\n", dep.Owner, dep.Repo, release.GetTagName(), platform)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, output := range outputs {
+		mark := " "
+		if output.MatchPlatform {
+			mark = "*"
+		}
+		fmt.Fprintf(w, "%s %s\t%s\t%d\n", mark, output.Name, tui.ByteCountIEC(int64(output.Size)), output.Downloads)
+	}
+	return w.Flush()
+}
+
+// parseRepository parses an [OWNER/]REPOSITORY[@TAG] argument, resolving registry packages and owners.
+func (rc *RootCommand) parseRepository(ctx context.Context, s string) (gpm.Dependency, error) {
+	deps, err := rc.GPM.ConvertDependenciesStrings(ctx, s)
+	if err != nil {
+		return gpm.Dependency{}, fmt.Errorf("failed to parse the argument: %w", err)
+	}
+	deps, err = rc.GPM.ResolveDependencies(ctx, deps[:1])
+	if err != nil {
+		return gpm.Dependency{}, err
+	}
+	return deps[0], nil
+}
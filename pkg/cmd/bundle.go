@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ctison/gpm/pkg/gpm"
+	"github.com/spf13/cobra"
+)
+
+func NewCommandBundle(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Use = "bundle"
+	cmd.Short = "Create and install bundles of assets for hosts without network access"
+
+	cmd.AddCommand(
+		NewCommandBundleCreate(rootCommand),
+		NewCommandBundleInstall(rootCommand),
+	)
+	return cmd
+}
+
+type BundleCreateCommand struct {
+	RootCommand *RootCommand
+	Output      string
+	Platforms   []string
+}
+
+func NewCommandBundleCreate(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Use = "create [[OWNER/]REPOSITORY[@TAG][:ASSET] [...]]"
+	cmd.Short = "Download assets for some platforms into a bundle (Defaults to the dependencies of the manifest)"
+	cmd.Long = cmd.Short + ".\n\nDependencies of the manifest are bundled at the versions recorded in its gpm.lock file." +
+		"\nThe bundle holds the assets, their checksums, their signatures and a lock file with their digests." +
+		"\nSignatures are not verified by gpm, they are bundled to be verified with tools like gpg or cosign."
+
+	bundleCreateCommand := BundleCreateCommand{
+		RootCommand: rootCommand,
+	}
+
+	cmd.Flags().StringVarP(&bundleCreateCommand.Output, "output", "o", "gpm-bundle.tar.gz", "Path of the bundle to create")
+	cmd.Flags().StringArrayVarP(&bundleCreateCommand.Platforms, "platform", "p", []string{gpm.CurrentPlatform().String()}, "Platform to bundle assets for, as OS/ARCH (eg. linux/arm64)")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return bundleCreateCommand.RunE(cmd, args)
+	}
+	return cmd
+}
+
+func (bundleCreateCommand BundleCreateCommand) RunE(cmd *cobra.Command, args []string) error {
+	var lockFile *gpm.LockFile
+	if len(args) == 0 {
+		config := bundleCreateCommand.RootCommand.Config
+		manifest, err := gpm.LoadManifest(config)
+		if err != nil {
+			return err
+		}
+		if len(manifest.Dependencies) == 0 {
+			return fmt.Errorf("no dependencies in manifest %q", config)
+		}
+		args = manifest.Dependencies
+		if lockFile, err = gpm.LoadLockFile(gpm.LockFilePath(config)); err != nil {
+			return err
+		}
+	}
+	platforms := make([]gpm.Platform, 0, len(bundleCreateCommand.Platforms))
+	for _, s := range bundleCreateCommand.Platforms {
+		platform, err := gpm.ParsePlatform(s)
+		if err != nil {
+			return err
+		}
+		platforms = append(platforms, platform)
+	}
+
+	f, err := os.Create(bundleCreateCommand.Output)
+	if err != nil {
+		return err
+	}
+	bundleLock, err := bundleCreateCommand.RootCommand.GPM.CreateBundle(cmd.Context(), f, args, platforms, lockFile)
+	if err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+	if err != nil {
+		os.Remove(bundleCreateCommand.Output)
+		return err
+	}
+	fmt.Printf("Bundled %d dependencies for %d platforms into %s\n", len(bundleLock.Dependencies), len(platforms), bundleCreateCommand.Output)
+	return nil
+}
+
+type BundleInstallCommand struct {
+	RootCommand *RootCommand
+}
+
+func NewCommandBundleInstall(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Use = "install BUNDLE"
+	cmd.Short = "Install the assets of a bundle for the current platform, without network access"
+	cmd.Long = cmd.Short + ".\n\nDigests of the assets are verified against the lock file of the bundle before installing anything."
+	cmd.Args = cobra.ExactArgs(1)
+
+	bundleInstallCommand := BundleInstallCommand{
+		RootCommand: rootCommand,
+	}
+
+	cmd.RunE = bundleInstallCommand.RunE
+	return cmd
+}
+
+func (bundleInstallCommand BundleInstallCommand) RunE(cmd *cobra.Command, args []string) error {
+	deps, err := bundleInstallCommand.RootCommand.GPM.InstallBundle(cmd.Context(), args[0])
+	for _, dep := range deps {
+		fmt.Println("Installed", dep.String())
+	}
+	return err
+}
@@ -1,6 +1,14 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/ctison/gpm/pkg/gpm"
 	"github.com/ctison/gpm/pkg/tui"
@@ -15,14 +23,41 @@ func NewCommand() *cobra.Command {
 	return cmd
 }
 
+// ExitCodeCanceled is the exit status of gpm when interrupted, as shells report processes killed by SIGINT.
+const ExitCodeCanceled = 130
+
 type RootCommand struct {
-	Verbose   bool
-	Debug     string
-	Config    string
-	HomePath  string
-	StorePath string
-	BinPath   string
-	GPM       *gpm.GPM
+	Verbose    bool
+	Debug      string
+	Config     string
+	HomePath   string
+	StorePath  string
+	BinPath    string
+	ConfigPath string
+	Registries []string
+	Global     bool
+	Retries    int
+	Timeout    time.Duration
+	MaxWait    time.Duration
+	Offline    bool
+	NoCache    bool
+	Shims      bool
+	// LockTimeout is the longest time to wait for another gpm process to release the store.
+	LockTimeout time.Duration
+	// Project is the path of the manifest of the project gpm runs in, if any.
+	Project string
+	GPM     *gpm.GPM
+	// commandOptions return options of the GPM specific to a subcommand. See [RootCommand.AddGPMOptions].
+	commandOptions map[*cobra.Command]func() []gpm.GPMOption
+}
+
+// AddGPMOptions adds the options returned by options to those the GPM is built with when cmd runs.
+// options is called once the flags of cmd are parsed.
+func (rc *RootCommand) AddGPMOptions(cmd *cobra.Command, options func() []gpm.GPMOption) {
+	if rc.commandOptions == nil {
+		rc.commandOptions = map[*cobra.Command]func() []gpm.GPMOption{}
+	}
+	rc.commandOptions[cmd] = options
 }
 
 func NewRootCommand() *cobra.Command {
@@ -31,21 +66,87 @@ func NewRootCommand() *cobra.Command {
 
 	rootCommand := &RootCommand{}
 
-	cobraCommand.AddCommand(NewCommandInstall(rootCommand), NewCommandList(rootCommand))
+	cobraCommand.AddCommand(
+		NewCommandInstall(rootCommand),
+		NewCommandList(rootCommand),
+		NewCommandSearch(rootCommand),
+		NewCommandReleases(rootCommand),
+		NewCommandAssets(rootCommand),
+		NewCommandImport(rootCommand),
+		NewCommandEnv(rootCommand),
+		NewCommandShellHook(rootCommand),
+		NewCommandUse(rootCommand),
+		NewCommandVersions(rootCommand),
+		NewCommandRollback(rootCommand),
+		NewCommandGC(rootCommand),
+		NewCommandDU(rootCommand),
+		NewCommandBundle(rootCommand),
+		NewCommandDoctor(rootCommand),
+		NewCommandWhich(rootCommand),
+		NewCommandInfo(rootCommand),
+		NewCommandRun(rootCommand),
+		NewCommandShim(rootCommand),
+		NewCommandSelfUpdate(rootCommand),
+	)
 
 	cobraCommand.PersistentFlags().BoolVarP(&rootCommand.Verbose, "verbose", "v", false, "Enable verbosity")
 	cobraCommand.PersistentFlags().StringVarP(&rootCommand.Debug, "debug", "d", "", "File path to write debugging logs")
-	cobraCommand.PersistentFlags().StringVarP(&rootCommand.Config, "config", "c", "gpm.yaml", "Configuration file")
+	cobraCommand.PersistentFlags().StringVarP(&rootCommand.Config, "config", "c", "gpm.yaml", "Manifest file listing the dependencies to install")
 	cobraCommand.PersistentFlags().StringVar(&rootCommand.HomePath, "home-dir", "", "Base path used to compute store dir and bin dir (Defaults to ~)")
 	cobraCommand.PersistentFlags().StringVar(&rootCommand.StorePath, "store-dir", "", "Base path used to store downloaded assets (Defaults to ~/.local/share/gpm)")
 	cobraCommand.PersistentFlags().StringVar(&rootCommand.BinPath, "bin-dir", "", "Directory where symlinks to executables will be created (Defaults to ~/.local/bin)")
+	cobraCommand.PersistentFlags().StringVar(&rootCommand.ConfigPath, "config-dir", "", "Directory of user configurations, like registries and mirrors.yaml (Defaults to ~/.config/gpm)")
+	cobraCommand.PersistentFlags().StringArrayVar(&rootCommand.Registries, "registry", nil, "Path or URL of a packages registry, takes precedence over registries of the config dir")
+
+	cobraCommand.PersistentFlags().IntVar(&rootCommand.Retries, "retries", gpm.DefaultRetryPolicy.Attempts, "Maximum attempts of requests failing with network or server errors")
+	cobraCommand.PersistentFlags().DurationVar(&rootCommand.Timeout, "timeout", gpm.DefaultRetryPolicy.Timeout, "Time to wait for a response to each request attempt (0 to wait forever)")
+	cobraCommand.PersistentFlags().DurationVar(&rootCommand.MaxWait, "max-wait", gpm.DefaultRetryPolicy.MaxWait, "Longest time to wait for a Github rate limit to reset before failing")
+	cobraCommand.PersistentFlags().DurationVar(&rootCommand.LockTimeout, "lock-timeout", gpm.DefaultLockTimeout, "Longest time to wait for another gpm process to release the store or bin dir (0 to wait forever)")
+	cobraCommand.PersistentFlags().BoolVar(&rootCommand.Offline, "offline", false, "Never access the network, install dependencies from the store only")
+	cobraCommand.PersistentFlags().BoolVar(&rootCommand.NoCache, "no-cache", false, "Don't cache Github API responses in the store")
+	cobraCommand.PersistentFlags().BoolVar(&rootCommand.Shims, "shims", os.Getenv("GPM_SHIMS") != "", "Write shims running the version pinned by the nearest manifest instead of symlinks (Defaults to true if $GPM_SHIMS is set)")
+	cobraCommand.PersistentFlags().BoolVarP(&rootCommand.Global, "global", "g", false, "Ignore the project manifest found in the current directory or its parents, and link into the global bin dir")
 
-	cobraCommand.PersistentPreRunE = func(_ *cobra.Command, _ []string) error {
-		rootCommand.GPM = gpm.NewGPM(
+	cobraCommand.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error {
+		if err := rootCommand.DetectProject(cmd); err != nil {
+			return err
+		}
+		opts := []gpm.GPMOption{
 			gpm.WithHomePath(rootCommand.HomePath),
 			gpm.WithBinPath(rootCommand.BinPath),
 			gpm.WithStorePath(rootCommand.StorePath),
-		)
+			gpm.WithConfigPath(rootCommand.ConfigPath),
+			gpm.WithRegistries(rootCommand.Registries...),
+			gpm.WithRetryPolicy(gpm.RetryPolicy{
+				Attempts:   rootCommand.Retries,
+				MinBackoff: gpm.DefaultRetryPolicy.MinBackoff,
+				MaxBackoff: gpm.DefaultRetryPolicy.MaxBackoff,
+				Timeout:    rootCommand.Timeout,
+				MaxWait:    rootCommand.MaxWait,
+			}),
+			gpm.WithLockTimeout(rootCommand.LockTimeout),
+			gpm.WithOffline(rootCommand.Offline),
+			gpm.WithHTTPCache(!rootCommand.NoCache),
+			gpm.WithShims(rootCommand.Shims),
+		}
+		configPath, err := gpm.ConfigPath(rootCommand.HomePath, rootCommand.ConfigPath)
+		if err != nil {
+			return fmt.Errorf("failed to get config path: %w", err)
+		}
+		mirrors, err := gpm.LoadMirrors(configPath)
+		if err != nil {
+			return err
+		}
+		if len(mirrors.Mirrors) > 0 {
+			opts = append(opts, gpm.WithMirrors(mirrors))
+		}
+		if isInteractive() {
+			opts = append(opts, gpm.WithOwnerChooser(promptOwner))
+		}
+		if options, ok := rootCommand.commandOptions[cmd]; ok {
+			opts = append(opts, options()...)
+		}
+		rootCommand.GPM = gpm.NewGPM(opts...)
 		return nil
 	}
 
@@ -54,6 +155,48 @@ func NewRootCommand() *cobra.Command {
 	return cobraCommand
 }
 
-func (rc RootCommand) RunE(_ *cobra.Command, _ []string) error {
-	return tea.NewProgram(tui.NewDashboardModel()).Start()
+// DetectProject enables the project mode when a manifest is found in the current directory or its
+// parents, or at the path of the --config flag. In project mode, executables are linked into the .gpm/bin
+// directory next to the manifest.
+func (rc *RootCommand) DetectProject(cmd *cobra.Command) error {
+	if rc.Global {
+		return nil
+	}
+	var manifestPath string
+	if cmd.Flags().Changed("config") {
+		if _, err := os.Stat(rc.Config); err != nil {
+			return nil
+		}
+		absPath, err := filepath.Abs(rc.Config)
+		if err != nil {
+			return err
+		}
+		manifestPath = absPath
+	} else {
+		foundPath, err := gpm.FindManifest(".", gpm.ManifestFileName)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		manifestPath = foundPath
+	}
+	rc.Config = manifestPath
+	rc.Project = manifestPath
+	if rc.BinPath == "" {
+		rc.BinPath = gpm.ProjectBinPath(manifestPath)
+	}
+	return nil
+}
+
+func (rc *RootCommand) RunE(_ *cobra.Command, _ []string) error {
+	return tea.NewProgram(tui.NewDashboardModel(*rc.GPM)).Start()
+}
+
+// printJSON writes v to w as indented JSON.
+func printJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
 }
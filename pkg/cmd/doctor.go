@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+type DoctorCommand struct {
+	RootCommand *RootCommand
+	Fix         bool
+	JSON        bool
+}
+
+func NewCommandDoctor(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Use = "doctor"
+	cmd.Short = "Check the installation for problems, like a bin dir missing from PATH or dangling links"
+	cmd.Long = cmd.Short + ".\n\nExits with status 1 when problems remain. With --fix, problems that can be are repaired:" +
+		"\ndangling links are removed, executables are made executable, missing directories are created," +
+		"\nand directories are made writable. Store entries without metadata are only reported, since the" +
+		"\ndigest of their asset can't be recovered from the extracted files."
+	cmd.Args = cobra.NoArgs
+
+	doctorCommand := DoctorCommand{
+		RootCommand: rootCommand,
+	}
+
+	cmd.Flags().BoolVar(&doctorCommand.Fix, "fix", false, "Repair the problems that can be")
+	cmd.Flags().BoolVar(&doctorCommand.JSON, "json", false, "Output in JSON")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return doctorCommand.RunE(cmd, args)
+	}
+	return cmd
+}
+
+func (doctorCommand DoctorCommand) RunE(cmd *cobra.Command, args []string) error {
+	problems, err := doctorCommand.RootCommand.GPM.Doctor(cmd.Context(), doctorCommand.Fix)
+	if err != nil {
+		return err
+	}
+	remaining := 0
+	for _, problem := range problems {
+		if !problem.Fixed {
+			remaining++
+		}
+	}
+	if doctorCommand.JSON {
+		if err := printJSON(os.Stdout, problems); err != nil {
+			return err
+		}
+	} else if len(problems) == 0 {
+		fmt.Println("No problem found")
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "STATUS\tCHECK\tPATH\tMESSAGE")
+		for _, problem := range problems {
+			status := "error"
+			switch {
+			case problem.Fixed:
+				status = "fixed"
+			case problem.Fixable:
+				status = "fixable"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", status, problem.Check, problem.Path, problem.Message)
+		}
+		w.Flush()
+	}
+	if remaining > 0 {
+		if !doctorCommand.JSON {
+			fmt.Printf("%d problems found\n", remaining)
+		}
+		os.Exit(1)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ctison/gpm/pkg/gpm"
+	"github.com/spf13/cobra"
+)
+
+// envProjectBin is the environment variable remembering the project bin dir added to PATH by gpm env.
+const envProjectBin = "GPM_PROJECT_BIN"
+
+var shells = []string{"bash", "zsh", "fish"}
+
+type EnvCommand struct {
+	RootCommand *RootCommand
+	Shell       string
+}
+
+func NewCommandEnv(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Use = "env"
+	cmd.Short = "Print shell commands that put the bin dir of the current project first in PATH"
+	cmd.Long = cmd.Short + ".\n\nThe bin dir of the previous project is removed from PATH, so that evaluating the output" +
+		"\nafter changing directory switches between projects. See gpm shell-hook to do it automatically."
+
+	envCommand := EnvCommand{
+		RootCommand: rootCommand,
+	}
+
+	cmd.Flags().StringVarP(&envCommand.Shell, "shell", "s", "", "Shell syntax, one of "+strings.Join(shells, ", ")+" (Defaults to $SHELL)")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return envCommand.RunE(cmd, args)
+	}
+	return cmd
+}
+
+func (envCommand EnvCommand) RunE(cmd *cobra.Command, args []string) error {
+	shell, err := detectShell(envCommand.Shell)
+	if err != nil {
+		return err
+	}
+	projectBin := ""
+	if envCommand.RootCommand.Project != "" {
+		projectBin = envCommand.RootCommand.BinPath
+	}
+	dirs := gpm.SwapPathDir(os.Getenv("PATH"), os.Getenv(envProjectBin), projectBin)
+	switch shell {
+	case "fish":
+		quoted := make([]string, 0, len(dirs))
+		for _, dir := range dirs {
+			quoted = append(quoted, quoteFish(dir))
+		}
+		fmt.Printf("set -gx PATH %s;\n", strings.Join(quoted, " "))
+		if projectBin != "" {
+			fmt.Printf("set -gx %s %s;\n", envProjectBin, quoteFish(projectBin))
+		} else {
+			fmt.Printf("set -e %s;\n", envProjectBin)
+		}
+	default:
+		fmt.Printf("export PATH=%s;\n", quotePOSIX(strings.Join(dirs, string(filepath.ListSeparator))))
+		if projectBin != "" {
+			fmt.Printf("export %s=%s;\n", envProjectBin, quotePOSIX(projectBin))
+		} else {
+			fmt.Printf("unset %s;\n", envProjectBin)
+		}
+	}
+	return nil
+}
+
+type ShellHookCommand struct {
+	RootCommand *RootCommand
+}
+
+func NewCommandShellHook(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Use = "shell-hook [" + strings.Join(shells, "|") + "]"
+	cmd.Short = "Print a shell hook that runs gpm env when changing directory"
+	cmd.Long = cmd.Short + ".\n\nAdd to your shell configuration:" +
+		"\n  bash (~/.bashrc):                 eval \"$(gpm shell-hook bash)\"" +
+		"\n  zsh (~/.zshrc):                   eval \"$(gpm shell-hook zsh)\"" +
+		"\n  fish (~/.config/fish/config.fish): gpm shell-hook fish | source"
+	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.ValidArgs = shells
+
+	shellHookCommand := ShellHookCommand{
+		RootCommand: rootCommand,
+	}
+
+	cmd.RunE = shellHookCommand.RunE
+	return cmd
+}
+
+func (shellHookCommand ShellHookCommand) RunE(cmd *cobra.Command, args []string) error {
+	shell := ""
+	if len(args) > 0 {
+		shell = args[0]
+	}
+	shell, err := detectShell(shell)
+	if err != nil {
+		return err
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get gpm executable path: %w", err)
+	}
+	switch shell {
+	case "bash":
+		fmt.Printf(`_gpm_hook() {
+  local previous_exit_status=$?
+  eval "$(%s env --shell bash)"
+  return $previous_exit_status
+}
+if [[ ";${PROMPT_COMMAND[*]:-};" != *";_gpm_hook;"* ]]; then
+  PROMPT_COMMAND="_gpm_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+`, quotePOSIX(executable))
+	case "zsh":
+		fmt.Printf(`_gpm_hook() {
+  eval "$(%s env --shell zsh)"
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook chpwd _gpm_hook
+_gpm_hook
+`, quotePOSIX(executable))
+	case "fish":
+		fmt.Printf(`function _gpm_hook --on-variable PWD
+  %s env --shell fish | source
+end
+_gpm_hook
+`, quoteFish(executable))
+	}
+	return nil
+}
+
+// detectShell validates shell, defaulting to the basename of $SHELL.
+func detectShell(shell string) (string, error) {
+	if shell == "" {
+		shell = filepath.Base(os.Getenv("SHELL"))
+	}
+	for _, supported := range shells {
+		if shell == supported {
+			return shell, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported shell %q, must be one of %s", shell, strings.Join(shells, ", "))
+}
+
+func quotePOSIX(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func quoteFish(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
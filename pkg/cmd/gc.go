@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ctison/gpm/pkg/gpm"
+	"github.com/ctison/gpm/pkg/tui"
+	"github.com/spf13/cobra"
+)
+
+type GCCommand struct {
+	RootCommand *RootCommand
+	KeepLast    int
+	OlderThan   string
+	DryRun      bool
+}
+
+func NewCommandGC(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Use = "gc"
+	cmd.Short = "Remove downloaded assets that are not linked nor required by a manifest"
+	cmd.Long = cmd.Short + ".\n\nAssets linked from a bin dir gpm linked into, or required by the manifest of a project" +
+		"\ngpm linked into, are always kept. A manifest dependency without tag keeps its latest download."
+	cmd.Args = cobra.NoArgs
+
+	gcCommand := GCCommand{
+		RootCommand: rootCommand,
+	}
+
+	cmd.Flags().IntVar(&gcCommand.KeepLast, "keep-last", 0, "Keep the N most recently downloaded versions of each repository")
+	cmd.Flags().StringVar(&gcCommand.OlderThan, "older-than", "", "Only remove versions downloaded before this age (eg. 30d, 12h)")
+	cmd.Flags().BoolVarP(&gcCommand.DryRun, "dry-run", "n", false, "Show what would be removed without removing anything")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return gcCommand.RunE(cmd, args)
+	}
+	return cmd
+}
+
+func (gcCommand GCCommand) RunE(cmd *cobra.Command, args []string) error {
+	policy := gpm.GCPolicy{
+		KeepLast: gcCommand.KeepLast,
+		DryRun:   gcCommand.DryRun,
+	}
+	if gcCommand.OlderThan != "" {
+		age, err := parseAge(gcCommand.OlderThan)
+		if err != nil {
+			return fmt.Errorf("invalid --older-than %q: %w", gcCommand.OlderThan, err)
+		}
+		policy.OlderThan = age
+	}
+	if project := gcCommand.RootCommand.Project; project != "" {
+		policy.Manifests = append(policy.Manifests, project)
+	}
+	removed, err := gcCommand.RootCommand.GPM.GC(cmd.Context(), policy)
+	var reclaimed int64
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, entry := range removed {
+		reclaimed += entry.Size
+		fmt.Fprintf(w, "%s\t%s\n", entry.Dependency.String(), tui.ByteCountIEC(entry.Size))
+	}
+	w.Flush()
+	if err != nil {
+		return err
+	}
+	if gcCommand.DryRun {
+		fmt.Printf("Would reclaim %s from %d assets\n", tui.ByteCountIEC(reclaimed), len(removed))
+	} else {
+		fmt.Printf("Reclaimed %s from %d assets\n", tui.ByteCountIEC(reclaimed), len(removed))
+	}
+	return nil
+}
+
+// parseAge parses a duration like [time.ParseDuration], also accepting a number of days like 30d.
+func parseAge(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		n, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+type DUCommand struct {
+	RootCommand *RootCommand
+	JSON        bool
+}
+
+type duOutput struct {
+	Repository string `json:"repository"`
+	Versions   int    `json:"versions"`
+	Assets     int    `json:"assets"`
+	Size       int64  `json:"size"`
+}
+
+func NewCommandDU(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Use = "du"
+	cmd.Short = "Show the disk usage of downloaded assets by repository"
+	cmd.Args = cobra.NoArgs
+
+	duCommand := DUCommand{
+		RootCommand: rootCommand,
+	}
+
+	cmd.Flags().BoolVar(&duCommand.JSON, "json", false, "Output in JSON")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return duCommand.RunE(cmd, args)
+	}
+	return cmd
+}
+
+func (duCommand DUCommand) RunE(cmd *cobra.Command, args []string) error {
+	entries, err := duCommand.RootCommand.GPM.ListStoreEntries(cmd.Context())
+	if err != nil {
+		return err
+	}
+	outputs := []duOutput{}
+	indexes := map[string]int{}
+	versions := map[string]bool{}
+	var total int64
+	for _, entry := range entries {
+		repo := entry.Owner + "/" + entry.Repo
+		i, ok := indexes[repo]
+		if !ok {
+			i = len(outputs)
+			indexes[repo] = i
+			outputs = append(outputs, duOutput{Repository: repo})
+		}
+		if version := repo + "@" + entry.ReleaseTag; !versions[version] {
+			versions[version] = true
+			outputs[i].Versions++
+		}
+		outputs[i].Assets++
+		outputs[i].Size += entry.Size
+		total += entry.Size
+	}
+	sort.SliceStable(outputs, func(i, j int) bool { return outputs[i].Size > outputs[j].Size })
+	if duCommand.JSON {
+		return printJSON(os.Stdout, outputs)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REPOSITORY\tVERSIONS\tASSETS\tSIZE")
+	for _, output := range outputs {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", output.Repository, output.Versions, output.Assets, tui.ByteCountIEC(output.Size))
+	}
+	fmt.Fprintf(w, "TOTAL\t\t%d\t%s\n", len(entries), tui.ByteCountIEC(total))
+	return w.Flush()
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ctison/gpm/pkg/gpm"
+	"github.com/spf13/cobra"
+)
+
+type ImportCommand struct {
+	RootCommand *RootCommand
+}
+
+func NewCommandImport(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Use = "import [FILE...]"
+	cmd.Short = "Import tools from aqua.yaml, .tool-versions or mise.toml files into the manifest"
+	cmd.Long = cmd.Short + ".\n\nWithout FILE, the files of the current directory named " + fmt.Sprint(gpm.ToolsFilesNames) + " are imported." +
+		"\nImported dependencies are merged into the manifest set by --config."
+
+	importCommand := ImportCommand{
+		RootCommand: rootCommand,
+	}
+
+	cmd.RunE = importCommand.RunE
+	return cmd
+}
+
+func (importCommand ImportCommand) RunE(cmd *cobra.Command, args []string) error {
+	files := args
+	if len(files) == 0 {
+		for _, name := range gpm.ToolsFilesNames {
+			if _, err := os.Stat(name); err == nil {
+				files = append(files, name)
+			}
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no tools file found in the current directory")
+		}
+	}
+
+	var tools []gpm.Tool
+	for _, file := range files {
+		fileTools, err := gpm.ParseToolsFile(file)
+		if err != nil {
+			return err
+		}
+		tools = append(tools, fileTools...)
+	}
+
+	deps, unmapped, err := importCommand.RootCommand.GPM.MapTools(cmd.Context(), tools)
+	if err != nil {
+		return err
+	}
+
+	manifestPath := importCommand.RootCommand.Config
+	manifest, err := gpm.LoadManifest(manifestPath)
+	if errors.Is(err, os.ErrNotExist) {
+		manifest, err = &gpm.Manifest{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for _, dep := range manifest.Dependencies {
+		existing[dep] = true
+	}
+	added := 0
+	for _, dep := range deps {
+		if !existing[dep] {
+			manifest.Dependencies = append(manifest.Dependencies, dep)
+			added++
+		}
+	}
+	if err := manifest.Save(manifestPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d of %d tools into %s\n", added, len(tools), manifestPath)
+	if len(unmapped) > 0 {
+		fmt.Println("Tools that could not be imported:")
+		for _, u := range unmapped {
+			name := u.Tool.Name
+			if u.Tool.Version != "" {
+				name += "@" + u.Tool.Version
+			}
+			fmt.Printf("  %s (%s): %s\n", name, u.Tool.Source, u.Reason)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ctison/gpm/pkg/gpm"
+	"github.com/ctison/gpm/pkg/tui"
+	"github.com/spf13/cobra"
+)
+
+type WhichCommand struct {
+	RootCommand *RootCommand
+	JSON        bool
+}
+
+func NewCommandWhich(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Use = "which NAME"
+	cmd.Short = "Show the release asset an executable of the bin dir or PATH comes from"
+	cmd.Args = cobra.ExactArgs(1)
+
+	whichCommand := WhichCommand{
+		RootCommand: rootCommand,
+	}
+
+	cmd.Flags().BoolVar(&whichCommand.JSON, "json", false, "Output in JSON")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return whichCommand.RunE(cmd, args)
+	}
+	return cmd
+}
+
+func (whichCommand WhichCommand) RunE(cmd *cobra.Command, args []string) error {
+	info, err := whichCommand.RootCommand.GPM.Which(args[0])
+	if err != nil {
+		return err
+	}
+	if whichCommand.JSON {
+		return printJSON(os.Stdout, info)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Link:\t%s -> %s\n", info.Link, info.Target)
+	fmt.Fprintf(w, "Repository:\t%s/%s/%s\n", info.Host, info.Owner, info.Repo)
+	fmt.Fprintf(w, "Tag:\t%s\n", info.Tag)
+	fmt.Fprintf(w, "Asset:\t%s\n", info.Asset)
+	fmt.Fprintf(w, "File:\t%s\n", info.File)
+	if info.Metadata != nil {
+		fmt.Fprintf(w, "Installed:\t%s from %s\n", info.Metadata.InstalledAt.Format("2006-01-02 15:04:05"), metadataSource(info.Metadata))
+		fmt.Fprintf(w, "SHA256:\t%s\n", info.Metadata.SHA256)
+	}
+	return w.Flush()
+}
+
+type InfoCommand struct {
+	RootCommand *RootCommand
+	JSON        bool
+}
+
+type infoOutput struct {
+	Repository  string               `json:"repository"`
+	Description string               `json:"description,omitempty"`
+	License     string               `json:"license,omitempty"`
+	Homepage    string               `json:"homepage,omitempty"`
+	LatestTag   string               `json:"latest_tag,omitempty"`
+	Installed   []gpm.InstalledAsset `json:"installed"`
+}
+
+func NewCommandInfo(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Use = "info [OWNER/]REPOSITORY"
+	cmd.Short = "Show the installed versions of a repository and its metadata on Github"
+	cmd.Args = cobra.ExactArgs(1)
+
+	infoCommand := InfoCommand{
+		RootCommand: rootCommand,
+	}
+
+	cmd.Flags().BoolVar(&infoCommand.JSON, "json", false, "Output in JSON")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return infoCommand.RunE(cmd, args)
+	}
+	return cmd
+}
+
+func (infoCommand InfoCommand) RunE(cmd *cobra.Command, args []string) error {
+	dep, err := infoCommand.RootCommand.parseRepository(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+	if dep.Owner == "" {
+		return fmt.Errorf("failed to resolve owner of %q", dep.Repo)
+	}
+	installed, err := infoCommand.RootCommand.GPM.InstalledAssets(cmd.Context(), dep.Owner, dep.Repo)
+	if err != nil {
+		return err
+	}
+	output := infoOutput{
+		Repository: dep.Owner + "/" + dep.Repo,
+		Installed:  installed,
+	}
+	if !infoCommand.RootCommand.GPM.IsOffline() {
+		repository, err := infoCommand.RootCommand.GPM.GetRepository(cmd.Context(), dep.Owner, dep.Repo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: only showing installed versions: %s\n", err.Error())
+		} else {
+			output.Description = repository.GetDescription()
+			output.License = repository.GetLicense().GetSPDXID()
+			output.Homepage = repository.GetHomepage()
+			if release, err := infoCommand.RootCommand.GPM.GetRelease(cmd.Context(), dep.Owner, dep.Repo, "latest"); err == nil {
+				output.LatestTag = release.GetTagName()
+			}
+		}
+	}
+
+	if infoCommand.JSON {
+		return printJSON(os.Stdout, output)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Repository:\t%s\n", output.Repository)
+	for _, field := range [][2]string{
+		{"Description", output.Description},
+		{"License", output.License},
+		{"Homepage", output.Homepage},
+		{"Latest", output.LatestTag},
+	} {
+		if field[1] != "" {
+			fmt.Fprintf(w, "%s:\t%s\n", field[0], field[1])
+		}
+	}
+	w.Flush()
+	if len(installed) == 0 {
+		fmt.Println("Not installed")
+		return nil
+	}
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TAG\tASSET\tSIZE\tINSTALLED\tVERIFIED\tLINKS")
+	for _, asset := range installed {
+		installedAt, verified := "-", "unknown"
+		if asset.Metadata != nil {
+			installedAt = asset.Metadata.InstalledAt.Format("2006-01-02 15:04")
+			verified = "no"
+			if asset.Metadata.VerifiedWith != "" {
+				verified = asset.Metadata.VerifiedWith
+			}
+		}
+		links := "-"
+		if len(asset.Links) > 0 {
+			links = strings.Join(asset.Links, ", ")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", asset.Tag, asset.Asset, tui.ByteCountIEC(asset.Size), installedAt, verified, links)
+	}
+	return w.Flush()
+}
+
+// metadataSource returns where the asset of metadata was downloaded from.
+func metadataSource(metadata *gpm.EntryMetadata) string {
+	if metadata.Source != "" {
+		return metadata.Source
+	}
+	return metadata.URL
+}
@@ -15,34 +15,98 @@ import (
 type InstallCommand struct {
 	RootCommand *RootCommand
 	Name        string
+	Jobs        int
+	JobsPerHost int
+	Update      bool
+	NoHooks     bool
+	RemoteHooks bool
 }
 
 func NewCommandInstall(rootCommand *RootCommand) *cobra.Command {
 	cmd := NewCommand()
 
 	cmd.Aliases = []string{"i"}
-	cmd.Use = "install [OWNER/]REPOSITORY[@TAG][:ARTIFACT[,...]] [...]"
-	cmd.Short = "Install release assets"
+	cmd.Use = "install [[OWNER/]REPOSITORY[@TAG][:ARTIFACT[,...]] [...]]"
+	cmd.Short = "Install release assets (Defaults to the dependencies of the manifest)"
+	cmd.Long = cmd.Short + ".\n\nDependencies of the manifest are installed at the versions recorded in the gpm.lock file" +
+		"\nnext to it, and the lock file is updated with the versions installed." +
+		"\n\nTAG is a release tag, a channel or a version constraint. Channels are latest (the default), stable" +
+		"\n(newest release that is not a prerelease) and prerelease (newest release). Constraints like ^1.4," +
+		"\n~2.3.0 or \">=0.9 <1.0\" select the highest matching version, which is recorded in the lock file." +
+		"\nAssets of moving tags like nightly are downloaded again when they change, and stored under the tag" +
+		"\nstamped with their digest (eg. nightly+0123456789ab)." +
+		"\n\nThe smoke tests and post-install hooks of registry packages are run once the executables are linked," +
+		"\nwith a minimal environment that leaves out variables like GITHUB_TOKEN. Those of registries at HTTP" +
+		"\nURLs only run with --remote-hooks. Hooks are not run by gpm use, gpm rollback or gpm bundle install."
 
 	installCommand := InstallCommand{
 		RootCommand: rootCommand,
 	}
 
 	cmd.LocalFlags().StringVarP(&installCommand.Name, "name", "n", "", "Override the name of the installed executable (Default to repository name)")
+	cmd.Flags().IntVarP(&installCommand.Jobs, "jobs", "j", 4, "Maximum number of concurrent downloads and API calls")
+	cmd.Flags().BoolVarP(&installCommand.Update, "update", "u", false, "Resolve dependencies of the manifest again instead of using versions of the lock file")
+	cmd.Flags().IntVar(&installCommand.JobsPerHost, "jobs-per-host", 0, "Maximum number of concurrent requests to the same host (0 for --jobs)")
+	cmd.Flags().BoolVar(&installCommand.NoHooks, "no-hooks", false, "Do not run the smoke tests and post-install hooks of packages")
+	cmd.Flags().BoolVar(&installCommand.RemoteHooks, "remote-hooks", false, "Also run the smoke tests and post-install hooks of packages from registries at HTTP URLs")
+
+	rootCommand.AddGPMOptions(cmd, func() []gpm.GPMOption {
+		return []gpm.GPMOption{
+			gpm.WithScheduler(gpm.NewScheduler(installCommand.Jobs, installCommand.JobsPerHost)),
+			gpm.WithHooks(!installCommand.NoHooks),
+			gpm.WithRemoteHooks(installCommand.RemoteHooks),
+		}
+	})
 
-	cmd.RunE = installCommand.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return installCommand.RunE(cmd, args)
+	}
 	return cmd
 }
 
 func (installCommand InstallCommand) RunE(cmd *cobra.Command, args []string) error {
+	var lockFile *gpm.LockFile
+	// specs are the manifest strings of deps, by index of deps.
+	var specs []string
+	var deps []gpm.Dependency
+	var err error
+	lockFilePath := gpm.LockFilePath(installCommand.RootCommand.Config)
 	if len(args) == 0 {
-		return fmt.Errorf("install from manifest is not yet implemented")
+		manifest, err := gpm.LoadManifest(installCommand.RootCommand.Config)
+		if err != nil {
+			return err
+		}
+		if len(manifest.Dependencies) == 0 {
+			return fmt.Errorf("no dependencies in manifest %q", installCommand.RootCommand.Config)
+		}
+		if lockFile, err = gpm.LoadLockFile(lockFilePath); err != nil {
+			return err
+		}
+		deps, specs, err = installCommand.RootCommand.GPM.ConvertManifestDependencies(cmd.Context(), *manifest)
+		if err != nil {
+			return fmt.Errorf("failed to parse the manifest: %w", err)
+		}
+	} else {
+		deps, err = installCommand.RootCommand.GPM.ConvertDependenciesStrings(cmd.Context(), args...)
+		if err != nil {
+			return fmt.Errorf("failed to parse the argument(s): %w", err)
+		}
 	}
 
-	deps, err := gpm.ConvertDependenciesStrings(args...)
+	platform := gpm.CurrentPlatform()
+	if lockFile != nil {
+		for i := range deps {
+			if installCommand.Update {
+				deps[i] = lockFile.ApplyOwner(specs[i], deps[i])
+			} else {
+				deps[i] = lockFile.Apply(specs[i], deps[i], platform)
+			}
+		}
+	}
 
+	deps, err = installCommand.RootCommand.GPM.ResolveDependencies(cmd.Context(), deps)
 	if err != nil {
-		return fmt.Errorf("failed to parse the argument(s): %w", err)
+		return err
 	}
 
 	if debug := installCommand.RootCommand.Debug; debug != "" {
@@ -55,11 +119,27 @@ func (installCommand InstallCommand) RunE(cmd *cobra.Command, args []string) err
 		log.SetOutput(io.Discard)
 	}
 
-	m, err := tea.NewProgram(tui.NewInstallModel(*installCommand.RootCommand.GPM, deps...)).StartReturningModel()
+	installModel := tui.NewInstallModel(cmd.Context(), *installCommand.RootCommand.GPM, deps...)
+	m, err := tea.NewProgram(installModel).StartReturningModel()
+	installModel.Wait()
 	if err != nil {
 		return err
 	}
 
+	if lockFile != nil {
+		for i, dep := range m.(tui.InstallModel).Installed() {
+			if dep.Repo != "" {
+				lockFile.Record(specs[i], dep, platform)
+			}
+		}
+		if err := lockFile.Save(lockFilePath); err != nil {
+			return err
+		}
+	}
+
+	if m.(tui.InstallModel).Canceled() {
+		os.Exit(ExitCodeCanceled)
+	}
 	if m.(tui.InstallModel).Errored() {
 		os.Exit(1)
 	}
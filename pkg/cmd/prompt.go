@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v47/github"
+	"golang.org/x/term"
+)
+
+// isInteractive reports whether a user can answer prompts.
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// promptOwner asks the user which of the candidates to install. It implements [gpm.OwnerChooser].
+func promptOwner(repo string, candidates []*github.Repository) (*github.Repository, error) {
+	fmt.Fprintf(os.Stderr, "Several repositories are named %q:\n", repo)
+	for i, candidate := range candidates {
+		fmt.Fprintf(os.Stderr, "  %d) %s (%d stars) %s\n", i+1, candidate.GetFullName(), candidate.GetStargazersCount(), candidate.GetDescription())
+	}
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Fprintf(os.Stderr, "Choose [1-%d]: ", len(candidates))
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("failed to read answer: %w", err)
+		}
+		i, err := strconv.Atoi(strings.TrimSpace(line))
+		if err == nil && 1 <= i && i <= len(candidates) {
+			return candidates[i-1], nil
+		}
+	}
+}
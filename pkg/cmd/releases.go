@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type ReleasesCommand struct {
+	RootCommand *RootCommand
+	JSON        bool
+}
+
+type releaseOutput struct {
+	Name        string    `json:"name"`
+	Tag         string    `json:"tag"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      int       `json:"assets"`
+}
+
+func NewCommandReleases(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Use = "releases [OWNER/]REPOSITORY"
+	cmd.Short = "List releases of a Github repository"
+	cmd.Args = cobra.ExactArgs(1)
+
+	releasesCommand := ReleasesCommand{
+		RootCommand: rootCommand,
+	}
+
+	cmd.Flags().BoolVar(&releasesCommand.JSON, "json", false, "Output in JSON")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return releasesCommand.RunE(cmd, args)
+	}
+	return cmd
+}
+
+func (releasesCommand ReleasesCommand) RunE(cmd *cobra.Command, args []string) error {
+	dep, err := releasesCommand.RootCommand.parseRepository(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+	releases, err := releasesCommand.RootCommand.GPM.ListReleases(cmd.Context(), dep.Owner, dep.Repo)
+	if err != nil {
+		return err
+	}
+	outputs := make([]releaseOutput, 0, len(releases))
+	for _, release := range releases {
+		outputs = append(outputs, releaseOutput{
+			Name:        release.GetName(),
+			Tag:         release.GetTagName(),
+			Prerelease:  release.GetPrerelease(),
+			PublishedAt: release.GetPublishedAt().Time,
+			Assets:      len(release.Assets),
+		})
+	}
+	if releasesCommand.JSON {
+		return printJSON(os.Stdout, outputs)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TAG\tNAME\tPUBLISHED\tASSETS\tPRERELEASE")
+	for _, output := range outputs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%t\n", output.Tag, output.Name, output.PublishedAt.Local().Format(time.RFC822), output.Assets, output.Prerelease)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+type RunCommand struct {
+	RootCommand *RootCommand
+	Exec        string
+}
+
+func NewCommandRun(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Aliases = []string{"exec"}
+	cmd.Use = "run [OWNER/]REPOSITORY[@TAG][:ASSET] [--] [ARGS...]"
+	cmd.Short = "Run an executable of a release asset without linking it into the bin dir"
+	cmd.Long = cmd.Short + ".\n\nThe asset is downloaded into the store if missing. gpm is replaced by the executable," +
+		"\nwhich gets the arguments following the dependency and whose exit status is the one of gpm."
+	cmd.Args = cobra.MinimumNArgs(1)
+	// Flags following the dependency are arguments of the executable.
+	cmd.Flags().SetInterspersed(false)
+
+	runCommand := RunCommand{
+		RootCommand: rootCommand,
+	}
+
+	cmd.Flags().StringVarP(&runCommand.Exec, "exec", "e", "", "Name of the executable to run, for assets with several (Defaults to repository name)")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return runCommand.RunE(cmd, args)
+	}
+	return cmd
+}
+
+func (runCommand RunCommand) RunE(cmd *cobra.Command, args []string) error {
+	if debug := runCommand.RootCommand.Debug; debug != "" {
+		f, err := tea.LogToFile(debug, "")
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", debug, err)
+		}
+		defer f.Close()
+	} else if !runCommand.RootCommand.Verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	deps, err := runCommand.RootCommand.GPM.ConvertDependenciesStrings(cmd.Context(), args[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse the argument: %w", err)
+	}
+	deps, err = runCommand.RootCommand.GPM.ResolveDependencies(cmd.Context(), deps)
+	if err != nil {
+		return err
+	}
+	_, name, filePath, release, err := runCommand.RootCommand.GPM.RunExecutable(cmd.Context(), deps[0], runCommand.Exec)
+	if err != nil {
+		return err
+	}
+	// The lock of the store entry is released by exec, or if it fails.
+	defer release()
+	execArgs := args[1:]
+	if len(execArgs) > 0 && execArgs[0] == "--" {
+		execArgs = execArgs[1:]
+	}
+	if err := syscall.Exec(filePath, append([]string{name}, execArgs...), os.Environ()); err != nil {
+		return fmt.Errorf("failed to run %q: %w", filePath, err)
+	}
+	return nil
+}
+
+type ShimCommand struct {
+	RootCommand *RootCommand
+}
+
+func NewCommandShim(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Use = "shim NAME TARGET [ARGS...]"
+	cmd.Short = "Run the version of an executable pinned by the project of the current directory, used by shims"
+	cmd.Hidden = true
+	cmd.Args = cobra.MinimumNArgs(2)
+	cmd.Flags().SetInterspersed(false)
+
+	shimCommand := ShimCommand{
+		RootCommand: rootCommand,
+	}
+
+	cmd.RunE = shimCommand.RunE
+	return cmd
+}
+
+func (shimCommand ShimCommand) RunE(cmd *cobra.Command, args []string) error {
+	if debug := shimCommand.RootCommand.Debug; debug != "" {
+		f, err := tea.LogToFile(debug, "")
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", debug, err)
+		}
+		defer f.Close()
+	} else {
+		log.SetOutput(io.Discard)
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	name, target := args[0], args[1]
+	filePath, err := shimCommand.RootCommand.GPM.ResolveShim(cmd.Context(), dir, name, target)
+	if err != nil {
+		return err
+	}
+	if err := syscall.Exec(filePath, append([]string{name}, args[2:]...), os.Environ()); err != nil {
+		return fmt.Errorf("failed to run %q: %w", filePath, err)
+	}
+	return nil
+}
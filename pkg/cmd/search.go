@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ctison/gpm/pkg/gpm"
+	"github.com/spf13/cobra"
+)
+
+type SearchCommand struct {
+	RootCommand *RootCommand
+	Limit       int
+	JSON        bool
+}
+
+func NewCommandSearch(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Aliases = []string{"s"}
+	cmd.Use = "search QUERY..."
+	cmd.Short = "Search Github repositories"
+	cmd.Args = cobra.MinimumNArgs(1)
+
+	searchCommand := SearchCommand{
+		RootCommand: rootCommand,
+	}
+
+	cmd.Flags().IntVarP(&searchCommand.Limit, "limit", "l", 10, "Maximum number of repositories to inspect")
+	cmd.Flags().BoolVar(&searchCommand.JSON, "json", false, "Output in JSON")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return searchCommand.RunE(cmd, args)
+	}
+	return cmd
+}
+
+func (searchCommand SearchCommand) RunE(cmd *cobra.Command, args []string) error {
+	platform := gpm.CurrentPlatform()
+	results, err := searchCommand.RootCommand.GPM.Search(cmd.Context(), strings.Join(args, " "), searchCommand.Limit, platform)
+	if err != nil {
+		return err
+	}
+	if searchCommand.JSON {
+		return printJSON(os.Stdout, results)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "REPOSITORY\tSTARS\tLATEST\t%s\n", strings.ToUpper(platform.String()))
+	for _, result := range results {
+		hasAsset := "no"
+		if result.HasPlatformAsset {
+			hasAsset = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", result.FullName, result.Stars, result.LatestRelease, hasAsset)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+type SelfUpdateCommand struct {
+	RootCommand *RootCommand
+	Check       bool
+	Force       bool
+	JSON        bool
+}
+
+func NewCommandSelfUpdate(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Use = "self-update"
+	cmd.Short = "Update gpm to its latest release"
+	cmd.Long = cmd.Short + ".\n\nThe release asset is verified against the checksums of the release. When gpm was installed" +
+		"\nwith gpm, the new version is installed into the store and the links to gpm are updated," +
+		"\notherwise the executable is replaced in place."
+	cmd.Args = cobra.NoArgs
+
+	selfUpdateCommand := SelfUpdateCommand{
+		RootCommand: rootCommand,
+	}
+
+	cmd.Flags().BoolVar(&selfUpdateCommand.Check, "check", false, "Only check whether an update is available")
+	cmd.Flags().BoolVar(&selfUpdateCommand.Force, "force", false, "Install the latest release even if it is not newer")
+	cmd.Flags().BoolVar(&selfUpdateCommand.JSON, "json", false, "Output in JSON")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return selfUpdateCommand.RunE(cmd, args)
+	}
+	return cmd
+}
+
+func (selfUpdateCommand SelfUpdateCommand) RunE(cmd *cobra.Command, args []string) error {
+	update, err := selfUpdateCommand.RootCommand.GPM.SelfUpdate(cmd.Context(), cmd.Root().Version, selfUpdateCommand.Check, selfUpdateCommand.Force, nil)
+	if err != nil {
+		return err
+	}
+	if selfUpdateCommand.JSON {
+		return printJSON(os.Stdout, update)
+	}
+	switch {
+	case update.Updated:
+		fmt.Printf("Updated gpm from %s to %s\n", update.CurrentVersion, update.LatestVersion)
+		for _, link := range update.Links {
+			fmt.Printf("Linked %s\n", link)
+		}
+	case update.Available:
+		fmt.Printf("gpm %s is available (current: %s), run gpm self-update\n", update.LatestVersion, update.CurrentVersion)
+	default:
+		fmt.Printf("gpm %s is up to date (latest: %s)\n", update.CurrentVersion, update.LatestVersion)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+type UseCommand struct {
+	RootCommand *RootCommand
+}
+
+func NewCommandUse(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Use = "use [OWNER/]REPOSITORY@TAG[:ASSET]"
+	cmd.Short = "Link executables of an already downloaded version, without network access"
+	cmd.Args = cobra.ExactArgs(1)
+
+	useCommand := UseCommand{
+		RootCommand: rootCommand,
+	}
+
+	cmd.RunE = useCommand.RunE
+	return cmd
+}
+
+func (useCommand UseCommand) RunE(cmd *cobra.Command, args []string) error {
+	deps, err := useCommand.RootCommand.GPM.ConvertLocalDependenciesStrings(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse the argument: %w", err)
+	}
+	dep, err := useCommand.RootCommand.GPM.Use(cmd.Context(), deps[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println("Using", dep.String())
+	return nil
+}
+
+type RollbackCommand struct {
+	RootCommand *RootCommand
+}
+
+func NewCommandRollback(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Use = "rollback EXECUTABLE"
+	cmd.Short = "Link again the version an executable of the bin dir pointed to before its last change"
+	cmd.Args = cobra.ExactArgs(1)
+
+	rollbackCommand := RollbackCommand{
+		RootCommand: rootCommand,
+	}
+
+	cmd.RunE = rollbackCommand.RunE
+	return cmd
+}
+
+func (rollbackCommand RollbackCommand) RunE(cmd *cobra.Command, args []string) error {
+	dep, err := rollbackCommand.RootCommand.GPM.Rollback(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println("Rolled back to", dep.String())
+	return nil
+}
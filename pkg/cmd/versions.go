@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ctison/gpm/pkg/gpm"
+	"github.com/google/go-github/v47/github"
+	"github.com/spf13/cobra"
+)
+
+type VersionsCommand struct {
+	RootCommand *RootCommand
+	JSON        bool
+}
+
+func NewCommandVersions(rootCommand *RootCommand) *cobra.Command {
+	cmd := NewCommand()
+
+	cmd.Use = "versions [OWNER/]REPOSITORY"
+	cmd.Short = "List downloaded and published versions of a Github repository (* marks the linked one)"
+	cmd.Args = cobra.ExactArgs(1)
+
+	versionsCommand := VersionsCommand{
+		RootCommand: rootCommand,
+	}
+
+	cmd.Flags().BoolVar(&versionsCommand.JSON, "json", false, "Output in JSON")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return versionsCommand.RunE(cmd, args)
+	}
+	return cmd
+}
+
+func (versionsCommand VersionsCommand) RunE(cmd *cobra.Command, args []string) error {
+	dep, err := versionsCommand.RootCommand.parseRepository(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+	cached, err := versionsCommand.RootCommand.GPM.CachedVersions(cmd.Context(), dep.Owner, dep.Repo)
+	if err != nil {
+		return err
+	}
+	cachedByTag := make(map[string]gpm.Version, len(cached))
+	for _, version := range cached {
+		cachedByTag[version.Tag] = version
+	}
+
+	// Published versions come first, newest first, followed by downloaded ones no longer published.
+	versions := make([]gpm.Version, 0, len(cached))
+	var releases []*github.RepositoryRelease
+	if !versionsCommand.RootCommand.GPM.IsOffline() {
+		releases, err = versionsCommand.RootCommand.GPM.ListAllReleases(cmd.Context(), dep.Owner, dep.Repo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: only listing downloaded versions: %s\n", err.Error())
+		}
+	}
+	for _, release := range releases {
+		version := cachedByTag[release.GetTagName()]
+		version.Tag = release.GetTagName()
+		version.Remote = true
+		versions = append(versions, version)
+		delete(cachedByTag, version.Tag)
+	}
+	for _, version := range cached {
+		if _, ok := cachedByTag[version.Tag]; ok {
+			versions = append(versions, version)
+		}
+	}
+
+	if versionsCommand.JSON {
+		return printJSON(os.Stdout, versions)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  TAG\tDOWNLOADED\tPUBLISHED")
+	for _, version := range versions {
+		mark := " "
+		if version.Active {
+			mark = "*"
+		}
+		fmt.Fprintf(w, "%s %s\t%t\t%t\n", mark, version.Tag, version.Cached, version.Remote)
+	}
+	return w.Flush()
+}
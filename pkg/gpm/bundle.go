@@ -0,0 +1,388 @@
+package gpm
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A bundle is a gzipped tarball holding the assets of dependencies for one or more platforms, so that
+// they can be installed on hosts without network access. Assets, their checksums and their signatures are
+// stored at their store path (github.com/OWNER/REPO/TAG/ASSET), followed by:
+const (
+	// bundleLockName is the lock file of the bundled dependencies, with the digests of their assets.
+	bundleLockName = LockFileName
+	// bundleRegistryName is a registry of the packages of the bundled dependencies, so that their
+	// executables are found without loading registries.
+	bundleRegistryName = "registry.yaml"
+)
+
+// signatureExtensions are the suffixes of the release assets signing other assets, bundled with them so
+// that they can be verified offline with tools like gpg, minisign or cosign, as gpm does not verify them.
+var signatureExtensions = []string{".sig", ".asc", ".minisig", ".pem", ".sigstore", ".sigstore.json"}
+
+// CreateBundle downloads the assets of the dependencies parsed from specs for each of platforms, with
+// their checksums when their package declares some and the signatures of both, and writes them as a
+// bundle to w. Dependencies
+// locked in lockFile, which may be nil, are bundled at their locked version. The lock file of the bundle
+// is returned. Since the lock file holds one asset per platform, specs listing several assets are rejected.
+func (gpm GPM) CreateBundle(ctx context.Context, w io.Writer, specs []string, platforms []Platform, lockFile *LockFile) (*LockFile, error) {
+	deps, depSpecs, err := gpm.ConvertManifestDependencies(ctx, Manifest{Dependencies: specs})
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i < len(depSpecs); i++ {
+		if depSpecs[i] == depSpecs[i-1] {
+			return nil, fmt.Errorf("cannot bundle several assets of %q, list each asset as its own dependency", depSpecs[i])
+		}
+	}
+	stagingPath, err := gpm.GetStagingPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gpm staging path: %w", err)
+	}
+	if err := os.MkdirAll(stagingPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory %q: %w", stagingPath, err)
+	}
+	tmp, err := os.MkdirTemp(stagingPath, "bundle-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+	bundleLock := LockFile{Dependencies: map[string]LockedDependency{}}
+	registry := Registry{Version: RegistryVersion, Packages: map[string]*Package{}}
+	bundled := map[string]string{}
+	for i, dep := range deps {
+		if lockFile != nil {
+			if locked, ok := lockFile.Dependencies[depSpecs[i]]; ok {
+				dep.Owner, dep.Repo, dep.ReleaseTag = locked.Owner, locked.Repo, locked.Tag
+			}
+		}
+		if dep.Owner == "" {
+			if dep.Owner, err = gpm.ResolveOwner(ctx, dep.Repo); err != nil {
+				return nil, fmt.Errorf("failed to resolve owner of %q: %w", dep, err)
+			}
+		}
+		dep.Package = gpm.packageOf(ctx, dep)
+		releaseCtx := ctx
+		if IsMovingTag(dep) {
+			releaseCtx = withRevalidation(ctx)
+		}
+		release, err := gpm.GetRelease(releaseCtx, dep.Owner, dep.Repo, dep.ReleaseTag)
+		if err != nil {
+			return nil, err
+		}
+		dep.ReleaseTag = release.GetTagName()
+		if dep.Package != nil {
+			registry.Packages[dep.Package.Name] = dep.Package
+		}
+		for _, platform := range platforms {
+			platformDep := dep
+			if lockFile != nil {
+				platformDep = lockFile.Apply(depSpecs[i], dep, platform)
+			}
+			asset, err := SelectAsset(release, platformDep, platform)
+			if err != nil {
+				return nil, err
+			}
+			platformDep.AssetName = asset.GetName()
+			assetPath := path.Join("github.com", dep.Owner, dep.Repo, dep.ReleaseTag, asset.GetName())
+			if digest, ok := bundled[assetPath]; ok {
+				bundleLock.record(depSpecs[i], platformDep, platform, digest)
+				continue
+			}
+			names := []string{asset.GetName()}
+			var checksumAssetName string
+			if dep.Package != nil {
+				if name, ok := dep.Package.ChecksumAsset(asset.GetName(), AssetsNames(release)); ok {
+					checksumAssetName = name
+					names = append(names, checksumAssetName)
+				}
+			}
+			names = append(names, signatureAssets(names, AssetsNames(release))...)
+			var digest string
+			for _, name := range names {
+				for _, releaseAsset := range release.Assets {
+					if releaseAsset.GetName() != name {
+						continue
+					}
+					filePath := filepath.Join(tmp, name)
+					err := gpm.retry(ctx, func(ctx context.Context) error {
+						return gpm.Download(ctx, releaseAsset.GetBrowserDownloadURL(), filePath, nil)
+					})
+					if err != nil {
+						return nil, fmt.Errorf("failed to download %q of %s/%s@%s: %w", name, dep.Owner, dep.Repo, dep.ReleaseTag, err)
+					}
+					switch name {
+					case asset.GetName():
+						if digest, _, err = fileSHA256(filePath); err != nil {
+							return nil, fmt.Errorf("failed to hash %q: %w", filePath, err)
+						}
+					case checksumAssetName:
+						checksums, err := os.ReadFile(filePath)
+						if err != nil {
+							return nil, err
+						}
+						if err := VerifyChecksum(filepath.Join(tmp, asset.GetName()), asset.GetName(), checksums); err != nil {
+							return nil, err
+						}
+					}
+				}
+			}
+			for _, name := range names {
+				filePath := filepath.Join(tmp, name)
+				if err := addBundleFile(tarWriter, path.Join("github.com", dep.Owner, dep.Repo, dep.ReleaseTag, name), filePath); err != nil {
+					return nil, err
+				}
+				if err := RemoveDownload(filePath); err != nil {
+					log.Printf("Failed to remove %q: %s", filePath, err.Error())
+				}
+			}
+			bundled[assetPath] = digest
+			bundleLock.record(depSpecs[i], platformDep, platform, digest)
+			log.Printf("Bundled %q for %s", platformDep, platform)
+		}
+	}
+
+	lockFilePath := filepath.Join(tmp, bundleLockName)
+	if err := bundleLock.Save(lockFilePath); err != nil {
+		return nil, err
+	}
+	if err := addBundleFile(tarWriter, bundleLockName, lockFilePath); err != nil {
+		return nil, err
+	}
+	registryData, err := yaml.Marshal(registry)
+	if err != nil {
+		return nil, err
+	}
+	registryPath := filepath.Join(tmp, bundleRegistryName)
+	if err := os.WriteFile(registryPath, registryData, 0644); err != nil {
+		return nil, err
+	}
+	if err := addBundleFile(tarWriter, bundleRegistryName, registryPath); err != nil {
+		return nil, err
+	}
+	if err := tarWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return &bundleLock, nil
+}
+
+// signatureAssets returns the names of assetsNames signing one of names.
+func signatureAssets(names, assetsNames []string) []string {
+	var signatures []string
+	for _, name := range names {
+		for _, ext := range signatureExtensions {
+			for _, assetName := range assetsNames {
+				if assetName == name+ext {
+					signatures = append(signatures, assetName)
+				}
+			}
+		}
+	}
+	return signatures
+}
+
+// record locks spec to dep on platform like [LockFile.Record], with the digest of its asset.
+func (lockFile *LockFile) record(spec string, dep Dependency, platform Platform, digest string) {
+	lockFile.Record(spec, dep, platform)
+	locked := lockFile.Dependencies[spec]
+	if locked.Digests == nil {
+		locked.Digests = map[string]string{}
+	}
+	locked.Digests[dep.AssetName] = digest
+	lockFile.Dependencies[spec] = locked
+}
+
+func addBundleFile(tarWriter *tar.Writer, name, filePath string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fileInfo, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     fileInfo.Size(),
+		Mode:     0644,
+		ModTime:  time.Now(),
+	}
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write %q to bundle: %w", name, err)
+	}
+	if _, err := io.Copy(tarWriter, f); err != nil {
+		return fmt.Errorf("failed to write %q to bundle: %w", name, err)
+	}
+	return nil
+}
+
+// InstallBundle installs the assets of the bundle at bundlePath for the current platform into the store
+// and links their executables, without network access. The digests of all the assets are verified against
+// the lock file of the bundle before anything is installed. It returns the installed dependencies.
+func (gpm GPM) InstallBundle(ctx context.Context, bundlePath string) ([]Dependency, error) {
+	stagingPath, err := gpm.GetStagingPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gpm staging path: %w", err)
+	}
+	if err := os.MkdirAll(stagingPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory %q: %w", stagingPath, err)
+	}
+	tmp, err := os.MkdirTemp(stagingPath, "bundle-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	if err := extractBundle(bundlePath, tmp); err != nil {
+		return nil, fmt.Errorf("failed to extract bundle %q: %w", bundlePath, err)
+	}
+
+	lockFilePath := filepath.Join(tmp, bundleLockName)
+	if _, err := os.Stat(lockFilePath); err != nil {
+		return nil, fmt.Errorf("%q is not a gpm bundle: %w", bundlePath, err)
+	}
+	lockFile, err := LoadLockFile(lockFilePath)
+	if err != nil {
+		return nil, err
+	}
+	var registries []*Registry
+	if data, err := os.ReadFile(filepath.Join(tmp, bundleRegistryName)); err == nil {
+		registry, err := ParseRegistry(data, bundlePath)
+		if err != nil {
+			return nil, err
+		}
+		registries = append(registries, registry)
+	}
+
+	platform := CurrentPlatform()
+	var deps []Dependency
+	for _, spec := range sortedKeys(lockFile.Dependencies) {
+		locked := lockFile.Dependencies[spec]
+		assetName, ok := locked.Assets[platform.String()]
+		if !ok {
+			return nil, fmt.Errorf("bundle %q has no asset of %q for %s", bundlePath, spec, platform)
+		}
+		dep := Dependency{Owner: locked.Owner, Repo: locked.Repo, ReleaseTag: locked.Tag, AssetName: assetName}
+		dep.Package = lookupPackageByRepo(registries, dep.Owner+"/"+dep.Repo)
+		filePath := filepath.Join(tmp, "github.com", dep.Owner, dep.Repo, dep.ReleaseTag, dep.AssetName)
+		digest, _, err := fileSHA256(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %q of bundle: %w", dep, err)
+		}
+		if expected := locked.Digests[assetName]; digest != expected {
+			return nil, fmt.Errorf("digest of %q is %s, but the bundle lock expects %q", dep, digest, expected)
+		}
+		if dep.Package != nil {
+			dirEntries, err := os.ReadDir(filepath.Dir(filePath))
+			if err != nil {
+				return nil, err
+			}
+			names := make([]string, 0, len(dirEntries))
+			for _, dirEntry := range dirEntries {
+				names = append(names, dirEntry.Name())
+			}
+			if checksumAssetName, ok := dep.Package.ChecksumAsset(assetName, names); ok {
+				checksums, err := os.ReadFile(filepath.Join(filepath.Dir(filePath), checksumAssetName))
+				if err != nil {
+					return nil, err
+				}
+				if err := VerifyChecksum(filePath, assetName, checksums); err != nil {
+					return nil, err
+				}
+			}
+		}
+		deps = append(deps, dep)
+	}
+
+	absPath, err := filepath.Abs(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	for i, dep := range deps {
+		unlock, err := gpm.lockAsset(ctx, dep)
+		if err != nil {
+			return deps[:i], err
+		}
+		metadata := EntryMetadata{
+			URL:          fmt.Sprintf("https://github.com/%s/%s/releases/download/%s/%s", dep.Owner, dep.Repo, dep.ReleaseTag, dep.AssetName),
+			Source:       "file://" + filepath.ToSlash(absPath),
+			VerifiedWith: bundleLockName + " of bundle",
+		}
+		stored, executables, err := gpm.storeAsset(ctx, dep, filepath.Join(tmp, "github.com", dep.Owner, dep.Repo, dep.ReleaseTag, dep.AssetName), metadata)
+		if err != nil {
+			unlock()
+			return deps[:i], err
+		}
+		deps[i] = stored
+		_, err = gpm.linkExecutables(ctx, executables)
+		unlock()
+		if err != nil {
+			return deps[:i], err
+		}
+	}
+	return deps, nil
+}
+
+// extractBundle extracts the regular files of the bundle at bundlePath into dir.
+func extractBundle(bundlePath, dir string) error {
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gzipReader, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(header.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid file name %q", header.Name)
+		}
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return err
+		}
+		out, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, tarReader); err != nil {
+			out.Close()
+			return err
+		}
+		if err := out.Close(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package gpm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// serverTransport sends all requests to a test server, whatever their host.
+type serverTransport struct {
+	server *httptest.Server
+}
+
+func (t serverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	u, _ := url.Parse(t.server.URL)
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = u.Scheme, u.Host
+	return t.server.Client().Transport.RoundTrip(req)
+}
+
+func TestBundle(t *testing.T) {
+	current := CurrentPlatform()
+	other := Platform{OS: "windows", Arch: "386"}
+	assets := map[string]string{
+		fmt.Sprintf("tool_%s_%s", current.OS, current.Arch): "current",
+		fmt.Sprintf("tool_%s_%s", other.OS, other.Arch):     "other",
+	}
+	var checksums strings.Builder
+	for name, content := range assets {
+		sum := sha256.Sum256([]byte(content))
+		fmt.Fprintf(&checksums, "%s  %s\n", hex.EncodeToString(sum[:]), name)
+	}
+	assets["checksums.txt"] = checksums.String()
+	currentAsset := fmt.Sprintf("tool_%s_%s", current.OS, current.Arch)
+	assets[currentAsset+".sig"] = "asset signature"
+	assets["checksums.txt.asc"] = "checksums signature"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/owner/tool/releases/tags/v1.0.0" {
+			var release struct {
+				TagName string `json:"tag_name"`
+				Assets  []any  `json:"assets"`
+			}
+			release.TagName = "v1.0.0"
+			for _, name := range sortedKeys(assets) {
+				release.Assets = append(release.Assets, map[string]string{"name": name, "browser_download_url": "https://github.com/download/" + name})
+			}
+			json.NewEncoder(w).Encode(release)
+			return
+		}
+		if content, ok := assets[strings.TrimPrefix(r.URL.Path, "/download/")]; ok {
+			w.Write([]byte(content))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	tmp := t.TempDir()
+	registryPath := filepath.Join(tmp, "registry.yaml")
+	registry := "version: 1\npackages:\n  tool:\n    repo: owner/tool\n    checksum: checksums.txt\n"
+	if err := os.WriteFile(registryPath, []byte(registry), 0644); err != nil {
+		t.Fatal(err)
+	}
+	online := NewGPM(
+		WithStorePath(filepath.Join(tmp, "build-store")),
+		WithConfigPath(filepath.Join(tmp, "config")),
+		WithRegistries(registryPath),
+		WithHTTPClient(&http.Client{Transport: serverTransport{server}}),
+		WithHTTPCache(false),
+		WithRetryPolicy(RetryPolicy{Attempts: 1}),
+	)
+	var bundle bytes.Buffer
+	lockFile, err := online.CreateBundle(context.Background(), &bundle, []string{"tool@v1.0.0"}, []Platform{current, other}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locked := lockFile.Dependencies["tool@v1.0.0"]; len(locked.Assets) != 2 || len(locked.Digests) != 2 {
+		t.Fatalf("CreateBundle() locked %+v, want 2 assets with digests", locked)
+	}
+
+	bundled := map[string]bool{}
+	rewriteBundle(t, bundle.Bytes(), func(name string, content []byte) []byte {
+		bundled[name] = true
+		return content
+	})
+	for _, name := range []string{currentAsset + ".sig", "checksums.txt.asc"} {
+		if !bundled["github.com/owner/tool/v1.0.0/"+name] {
+			t.Errorf("CreateBundle() did not bundle signature %q", name)
+		}
+	}
+
+	if _, err := online.CreateBundle(context.Background(), io.Discard, []string{"tool@v1.0.0:a,b"}, []Platform{current}, nil); err == nil {
+		t.Error("CreateBundle() bundled a dependency with several assets, want an error")
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(name string, content []byte) []byte
+		wantErr bool
+	}{
+		{"Install", nil, false},
+		{"Tampered asset", func(name string, content []byte) []byte {
+			if strings.HasPrefix(filepath.Base(name), "tool_"+current.OS) {
+				return []byte("tampered")
+			}
+			return content
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			bundlePath := filepath.Join(dir, "bundle.tar.gz")
+			if err := os.WriteFile(bundlePath, rewriteBundle(t, bundle.Bytes(), tt.modify), 0644); err != nil {
+				t.Fatal(err)
+			}
+			offline := NewGPM(
+				WithStorePath(filepath.Join(dir, "store")),
+				WithBinPath(filepath.Join(dir, "bin")),
+				WithConfigPath(filepath.Join(dir, "config")),
+				WithOffline(true),
+			)
+			deps, err := offline.InstallBundle(context.Background(), bundlePath)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("InstallBundle() installed %v, want error", deps)
+				}
+				if _, err := os.Stat(filepath.Join(dir, "bin", "tool")); !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("InstallBundle() linked tool despite error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := os.ReadFile(filepath.Join(dir, "bin", "tool")); string(got) != "current" {
+				t.Errorf("InstallBundle() linked tool to %q, want current", got)
+			}
+			entryPath := filepath.Join(dir, "store", "github.com", "owner", "tool", "v1.0.0", deps[0].AssetName)
+			if metadata, err := ReadEntryMetadata(entryPath); err != nil || metadata.Source != "file://"+filepath.ToSlash(bundlePath) {
+				t.Errorf("ReadEntryMetadata() = %+v, %v, want source %q", metadata, err, bundlePath)
+			}
+		})
+	}
+}
+
+// rewriteBundle returns the bundle with the content of its files replaced by modify, if not nil.
+func rewriteBundle(t *testing.T, bundle []byte, modify func(name string, content []byte) []byte) []byte {
+	if modify == nil {
+		return bundle
+	}
+	gzipReader, err := gzip.NewReader(bytes.NewReader(bundle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content = modify(header.Name, content)
+		header.Size = int64(len(content))
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		tarWriter.Write(content)
+	}
+	tarWriter.Close()
+	gzipWriter.Close()
+	return buf.Bytes()
+}
@@ -0,0 +1,260 @@
+package gpm
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// CacheTTL is how long a cached API response is used without asking Github whether it changed,
+// for the endpoints whose path matches Path.
+type CacheTTL struct {
+	Path *regexp.Regexp
+	TTL  time.Duration
+}
+
+// DefaultCacheTTLs are the TTLs of Github API endpoints used unless [WithCacheTTLs] is set.
+// Responses of other endpoints, and releases of moving tags (see [IsMovingTag]), are revalidated on
+// every request.
+var DefaultCacheTTLs = []CacheTTL{
+	{regexp.MustCompile(`^/repos/[^/]+/[^/]+/releases/tags/`), 24 * time.Hour},
+	{regexp.MustCompile(`^/repos/[^/]+/[^/]+/releases(/latest)?$`), 10 * time.Minute},
+	{regexp.MustCompile(`^/search/repositories$`), time.Hour},
+}
+
+// WithHTTPCache enables or disables the cache of Github API responses. Enabled by default.
+func WithHTTPCache(enabled bool) GPMOption {
+	return func(gpm *GPM) {
+		gpm.httpCache = enabled
+	}
+}
+
+// WithCacheTTLs sets the TTLs of cached Github API responses. See [DefaultCacheTTLs].
+func WithCacheTTLs(ttls []CacheTTL) GPMOption {
+	return func(gpm *GPM) {
+		gpm.cacheTTLs = ttls
+	}
+}
+
+// GetHTTPCachePath returns the directory where Github API responses are cached.
+func (gpm GPM) GetHTTPCachePath() (string, error) {
+	storePath, err := gpm.GetStorePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(storePath, ".cache", "api"), nil
+}
+
+// apiHTTPClient returns the HTTP client used for Github API requests, caching their responses on disk.
+func (gpm GPM) apiHTTPClient() *http.Client {
+	client := gpm.HTTPClient()
+	if !gpm.httpCache {
+		return client
+	}
+	cachePath, err := gpm.GetHTTPCachePath()
+	if err != nil {
+		log.Printf("Failed to get HTTP cache path: %s", err.Error())
+		return client
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &http.Client{
+		Transport:     cacheTransport{base: base, dir: cachePath, ttls: gpm.cacheTTLs},
+		CheckRedirect: client.CheckRedirect,
+		Jar:           client.Jar,
+		Timeout:       client.Timeout,
+	}
+}
+
+// cachedResponse is a response saved on disk by [cacheTransport].
+type cachedResponse struct {
+	URL        string      `json:"url"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	// StoredAt is when the response was last received or revalidated.
+	StoredAt time.Time `json:"stored_at"`
+}
+
+// cacheTransport caches successful GET responses in dir. Cached responses are served as is within
+// their TTL, then revalidated with conditional requests, which don't count against Github rate limits.
+// Cached responses are also served when the server can't be reached or fails.
+type cacheTransport struct {
+	base http.RoundTripper
+	dir  string
+	ttls []CacheTTL
+}
+
+func (t cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.base.RoundTrip(req)
+	}
+	entryPath := filepath.Join(t.dir, t.key(req)+".json")
+	entry, cached := readCachedResponse(entryPath)
+	if cached && time.Since(entry.StoredAt) < t.ttl(req) {
+		return entry.response(req), nil
+	}
+
+	if cached {
+		req = req.Clone(req.Context())
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := entry.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		if cached && req.Context().Err() == nil {
+			log.Printf("Serving stale cached response of %q: %s", req.URL, err.Error())
+			return entry.response(req), nil
+		}
+		return nil, err
+	}
+	switch {
+	case cached && res.StatusCode >= http.StatusInternalServerError:
+		log.Printf("Serving stale cached response of %q: %s", req.URL, res.Status)
+		res.Body.Close()
+		return entry.response(req), nil
+	case cached && res.StatusCode == http.StatusNotModified:
+		res.Body.Close()
+		entry.StoredAt = time.Now()
+		t.save(entryPath, entry)
+		return entry.response(req), nil
+	case res.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		res.Body = io.NopCloser(bytes.NewReader(body))
+		t.save(entryPath, cachedResponse{
+			URL:        req.URL.String(),
+			StatusCode: res.StatusCode,
+			Header:     res.Header,
+			Body:       body,
+			StoredAt:   time.Now(),
+		})
+	}
+	return res, nil
+}
+
+// key identifies the cached response of req by its URL and the headers that change the response.
+func (t cacheTransport) key(req *http.Request) string {
+	h := sha256.New()
+	for _, s := range []string{req.URL.String(), req.Header.Get("Accept"), req.Header.Get("Authorization")} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// revalidateKey marks the contexts of requests whose cached response [cacheTransport] must revalidate
+// whatever its TTL, like the release of a moving tag.
+type revalidateKey struct{}
+
+// withRevalidation returns a context whose API requests revalidate their cached response.
+func withRevalidation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, revalidateKey{}, true)
+}
+
+func (t cacheTransport) ttl(req *http.Request) time.Duration {
+	if req.Context().Value(revalidateKey{}) != nil {
+		return 0
+	}
+	for _, ttl := range t.ttls {
+		if ttl.Path.MatchString(req.URL.Path) {
+			return ttl.TTL
+		}
+	}
+	return 0
+}
+
+func (t cacheTransport) save(entryPath string, entry cachedResponse) {
+	if err := writeCachedResponse(entryPath, entry); err != nil {
+		log.Printf("Failed to cache response of %q: %s", entry.URL, err.Error())
+	}
+}
+
+func readCachedResponse(entryPath string) (cachedResponse, bool) {
+	var entry cachedResponse
+	data, err := os.ReadFile(entryPath)
+	if err != nil {
+		return entry, false
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		log.Printf("Ignoring invalid cached response %q: %s", entryPath, err.Error())
+		return entry, false
+	}
+	return entry, true
+}
+
+// writeCachedResponse writes entry to a temporary file renamed to entryPath, so that concurrent
+// readers never see a partial entry.
+func writeCachedResponse(entryPath string, entry cachedResponse) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(entryPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %q: %w", filepath.Dir(entryPath), err)
+	}
+	return writeFileAtomic(entryPath, data, 0600)
+}
+
+// writeFileAtomic writes data to a temporary file in the directory of filePath, then renames it to
+// filePath, so that readers never see a partial file and an interrupted write keeps the previous one.
+func writeFileAtomic(filePath string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), filePath); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+func (entry cachedResponse) response(req *http.Request) *http.Response {
+	header := entry.Header.Clone()
+	header.Set("X-From-Cache", "1")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.StatusCode, http.StatusText(entry.StatusCode)),
+		StatusCode:    entry.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+		ContentLength: int64(len(entry.Body)),
+		Request:       req,
+	}
+}
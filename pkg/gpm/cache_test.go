@@ -0,0 +1,128 @@
+package gpm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacheTransport(t *testing.T) {
+	var requests, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: cacheTransport{
+		base: http.DefaultTransport,
+		dir:  t.TempDir(),
+		ttls: []CacheTTL{{regexp.MustCompile(`^/fresh$`), time.Hour}},
+	}}
+	get := func(path string) string {
+		t.Helper()
+		res, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s status = %d", path, res.StatusCode)
+		}
+		return string(body)
+	}
+
+	tests := []struct {
+		name            string
+		path            string
+		wantRequests    int
+		wantNotModified int
+	}{
+		{"Fresh miss", "/fresh", 1, 0},
+		{"Fresh hit", "/fresh", 1, 0},
+		{"Revalidated miss", "/revalidated", 2, 0},
+		{"Revalidated hit", "/revalidated", 3, 1},
+	}
+	for _, tt := range tests {
+		if got := get(tt.path); got != tt.path {
+			t.Errorf("%s: body = %q, want %q", tt.name, got, tt.path)
+		}
+		if requests != tt.wantRequests || notModified != tt.wantNotModified {
+			t.Errorf("%s: requests = %d, not modified = %d, want %d, %d", tt.name, requests, notModified, tt.wantRequests, tt.wantNotModified)
+		}
+	}
+
+	server.Close()
+	if got := get("/revalidated"); got != "/revalidated" {
+		t.Errorf("stale body = %q, want %q", got, "/revalidated")
+	}
+}
+
+func TestResolveAsset_CachedMovingTag(t *testing.T) {
+	build, requests := 1, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		tag := strings.TrimPrefix(r.URL.Path, "/repos/owner/tool/releases/tags/")
+		etag := fmt.Sprintf(`"%s-%d"`, tag, build)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		json.NewEncoder(w).Encode(map[string]any{
+			"tag_name": tag,
+			"assets":   []map[string]any{{"id": build, "name": "tool_linux_amd64"}},
+		})
+	}))
+	defer server.Close()
+
+	gpm := NewGPM(
+		WithStorePath(t.TempDir()),
+		WithHTTPClient(&http.Client{Transport: serverTransport{server}}),
+		WithRetryPolicy(RetryPolicy{Attempts: 1}),
+	)
+	tests := []struct {
+		tag string
+		// wantRequests are the requests reaching Github when resolving tag after a new build.
+		wantRequests int
+		// wantID is the ID of the asset resolved after a new build.
+		wantID int64
+	}{
+		{"v1.0.0", 0, 1},
+		{"nightly", 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			build = 1
+			dep := Dependency{Owner: "owner", Repo: "tool", ReleaseTag: tt.tag, AssetName: "tool_linux_amd64"}
+			if _, _, err := gpm.ResolveAsset(context.Background(), &dep); err != nil {
+				t.Fatal(err)
+			}
+			build, requests = 2, 0
+			_, asset, err := gpm.ResolveAsset(context.Background(), &dep)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if requests != tt.wantRequests || asset.GetID() != tt.wantID {
+				t.Errorf("ResolveAsset() = asset %d after %d requests, want asset %d after %d", asset.GetID(), requests, tt.wantID, tt.wantRequests)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package gpm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v47/github"
+)
+
+// Release channels, accepted in place of a release tag.
+const (
+	// ChannelLatest is the latest stable release, as marked by Github.
+	ChannelLatest = "latest"
+	// ChannelStable is the latest release that is neither a prerelease nor a draft.
+	ChannelStable = "stable"
+	// ChannelPrerelease is the newest release, prereleases included. Drafts are ignored.
+	ChannelPrerelease = "prerelease"
+)
+
+// IsChannel reports whether tag names a release channel rather than a release. An empty tag is the
+// latest channel.
+func IsChannel(tag string) bool {
+	switch tag {
+	case "", ChannelLatest, ChannelStable, ChannelPrerelease:
+		return true
+	}
+	return false
+}
+
+// DefaultMovingTags are the release tags whose assets are usually replaced in place, like nightly builds.
+// Packages can declare others with [Package.MovingTags].
+var DefaultMovingTags = []string{"nightly", "edge", "canary", "tip", "continuous"}
+
+// stampSeparator separates a moving tag from the content stamp of its assets in store entries.
+const stampSeparator = "+"
+
+// regexpStamp matches the stamps of [StampTag], to tell them from the build metadata of semantic versions.
+var regexpStamp = regexp.MustCompile(`^[0-9a-f]{12}$`)
+
+// IsMovingTag reports whether the assets of the release tag of dep can change over time. Assets of
+// moving tags are stored under a content-stamped tag (eg. nightly+0123456789ab), so that a new build
+// never overwrites an entry in use. See [StampTag].
+func IsMovingTag(dep Dependency) bool {
+	tag, _ := SplitStampedTag(dep.ReleaseTag)
+	for _, moving := range DefaultMovingTags {
+		if strings.EqualFold(tag, moving) {
+			return true
+		}
+	}
+	if dep.Package != nil {
+		for _, moving := range dep.Package.MovingTags {
+			if tag == moving {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// StampTag returns the release tag of the store entry of an asset of the moving tag, whose SHA-256 digest
+// is sha256.
+func StampTag(tag, sha256 string) string {
+	if len(sha256) > 12 {
+		sha256 = sha256[:12]
+	}
+	return tag + stampSeparator + sha256
+}
+
+// SplitStampedTag splits a tag made by [StampTag] into the release tag and the stamp, which is empty
+// for other tags.
+func SplitStampedTag(tag string) (string, string) {
+	if i := strings.LastIndex(tag, stampSeparator); i >= 0 && regexpStamp.MatchString(tag[i+1:]) {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
+
+// getChannelRelease returns the newest release of the stable or prerelease channel.
+func (gpm GPM) getChannelRelease(ctx context.Context, owner, repo, channel string) (*github.RepositoryRelease, error) {
+	var found *github.RepositoryRelease
+	err := gpm.forEachRelease(ctx, owner, repo, func(release *github.RepositoryRelease) bool {
+		if !release.GetDraft() && (channel == ChannelPrerelease || !release.GetPrerelease()) {
+			found = release
+		}
+		return found == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no release of %s/%s in channel %s", owner, repo, channel)
+	}
+	return found, nil
+}
+
+// findStampedEntry returns the stamped tag of the store entry holding asset, an asset of the moving tag
+// of dep, if this exact asset was already downloaded. Assets are identified by their ID and update time.
+func (gpm GPM) findStampedEntry(dep Dependency, asset *github.ReleaseAsset) (string, bool) {
+	storePath, err := gpm.GetStorePath()
+	if err != nil {
+		return "", false
+	}
+	tag, _ := SplitStampedTag(dep.ReleaseTag)
+	tagPaths, err := filepath.Glob(filepath.Join(storePath, "github.com", dep.Owner, dep.Repo, tag+stampSeparator+"*"))
+	if err != nil {
+		return "", false
+	}
+	for _, tagPath := range tagPaths {
+		metadata, err := ReadEntryMetadata(filepath.Join(tagPath, dep.AssetName))
+		if err != nil {
+			continue
+		}
+		if metadata.AssetID == asset.GetID() && metadata.AssetUpdatedAt.Equal(asset.GetUpdatedAt().Time) {
+			if _, err := os.Stat(filepath.Join(tagPath, dep.AssetName)); err == nil {
+				return filepath.Base(tagPath), true
+			}
+		}
+	}
+	return "", false
+}
+
+// checkStamp fails if the asset of dep stored under stampedTag is not the one locked with lockedStamp.
+func checkStamp(dep Dependency, stampedTag, lockedStamp string) error {
+	if _, stamp := SplitStampedTag(stampedTag); lockedStamp != "" && stamp != lockedStamp {
+		return fmt.Errorf("asset %q of moving tag %s/%s@%s changed since it was locked with stamp %s, update the lock file",
+			dep.AssetName, dep.Owner, dep.Repo, dep.ReleaseTag, lockedStamp)
+	}
+	return nil
+}
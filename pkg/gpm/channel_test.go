@@ -0,0 +1,127 @@
+package gpm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSplitStampedTag(t *testing.T) {
+	tests := []struct {
+		tag, wantTag, wantStamp string
+	}{
+		{"nightly+0123456789ab", "nightly", "0123456789ab"},
+		{"nightly", "nightly", ""},
+		{"v1.0.0+build.1", "v1.0.0+build.1", ""},
+	}
+	for _, tt := range tests {
+		if tag, stamp := SplitStampedTag(tt.tag); tag != tt.wantTag || stamp != tt.wantStamp {
+			t.Errorf("SplitStampedTag(%q) = %q, %q, want %q, %q", tt.tag, tag, stamp, tt.wantTag, tt.wantStamp)
+		}
+	}
+}
+
+func TestChannels(t *testing.T) {
+	var mtx sync.Mutex
+	nightly := map[string]any{"id": 1, "updated_at": "2024-01-01T00:00:00Z", "content": "#!/bin/sh\necho build 1\n"}
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		release := func(tag string, prerelease, draft bool, assetID, updatedAt any) map[string]any {
+			return map[string]any{
+				"tag_name":   tag,
+				"prerelease": prerelease,
+				"draft":      draft,
+				"assets": []map[string]any{{
+					"id":                   assetID,
+					"name":                 "tool_linux_amd64",
+					"updated_at":           updatedAt,
+					"browser_download_url": "https://github.com/download/" + tag,
+				}},
+			}
+		}
+		switch r.URL.Path {
+		case "/repos/owner/tool/releases":
+			json.NewEncoder(w).Encode([]any{
+				release("v3.0.0", false, true, 30, "2024-01-01T00:00:00Z"),
+				release("v2.0.0-rc.1", true, false, 20, "2024-01-01T00:00:00Z"),
+				release("v1.0.0", false, false, 10, "2024-01-01T00:00:00Z"),
+			})
+		case "/repos/owner/tool/releases/tags/nightly":
+			json.NewEncoder(w).Encode(release("nightly", true, false, nightly["id"], nightly["updated_at"]))
+		case "/download/nightly":
+			downloads++
+			w.Write([]byte(nightly["content"].(string)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tmp := t.TempDir()
+	gpm := NewGPM(
+		WithStorePath(filepath.Join(tmp, "store")),
+		WithBinPath(filepath.Join(tmp, "bin")),
+		WithConfigPath(filepath.Join(tmp, "config")),
+		WithHTTPClient(&http.Client{Transport: serverTransport{server}}),
+		WithHTTPCache(false),
+		WithRetryPolicy(RetryPolicy{Attempts: 1}),
+	)
+	ctx := context.Background()
+
+	for channel, want := range map[string]string{ChannelStable: "v1.0.0", ChannelPrerelease: "v2.0.0-rc.1"} {
+		if release, err := gpm.GetRelease(ctx, "owner", "tool", channel); err != nil || release.GetTagName() != want {
+			t.Errorf("GetRelease(%q) = %q, %v, want %q", channel, release.GetTagName(), err, want)
+		}
+	}
+
+	install := func(tag string) (Dependency, error) {
+		dep, _, err := gpm.FetchDependency(ctx, Dependency{Owner: "owner", Repo: "tool", ReleaseTag: tag, AssetName: "tool_linux_amd64"}, nil)
+		return dep, err
+	}
+	first, err := install("nightly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag, stamp := SplitStampedTag(first.ReleaseTag); tag != "nightly" || stamp == "" {
+		t.Fatalf("FetchDependency() stored nightly as %q, want a stamped tag", first.ReleaseTag)
+	}
+	if again, err := install("nightly"); err != nil || again.ReleaseTag != first.ReleaseTag || downloads != 1 {
+		t.Errorf("FetchDependency() of unchanged nightly = %q, %v after %d downloads, want %q after 1", again.ReleaseTag, err, downloads, first.ReleaseTag)
+	}
+
+	mtx.Lock()
+	nightly = map[string]any{"id": 2, "updated_at": "2024-01-02T00:00:00Z", "content": "#!/bin/sh\necho build 2\n"}
+	mtx.Unlock()
+	second, err := install("nightly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ReleaseTag == first.ReleaseTag || downloads != 2 {
+		t.Errorf("FetchDependency() of replaced nightly = %q after %d downloads, want a new stamp after 2", second.ReleaseTag, downloads)
+	}
+	// The stamped tag of a lock file is installed from the store, even when nightly moved on.
+	if locked, err := install(first.ReleaseTag); err != nil || locked.ReleaseTag != first.ReleaseTag {
+		t.Errorf("FetchDependency(%q) = %q, %v", first.ReleaseTag, locked.ReleaseTag, err)
+	}
+	// A locked stamp missing from the store fails once the asset was replaced.
+	if _, err := install("nightly+0123456789ab"); err == nil || !strings.Contains(err.Error(), "changed since it was locked") {
+		t.Errorf("FetchDependency() of a replaced locked nightly error = %v, want a changed asset error", err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(tmp, "store", "github.com", "owner", "tool", first.ReleaseTag, "tool_linux_amd64"), past, past); err != nil {
+		t.Fatal(err)
+	}
+	offline := NewGPM(WithStorePath(filepath.Join(tmp, "store")), WithOffline(true))
+	if cached, err := offline.FindCachedDependency(ctx, Dependency{Owner: "owner", Repo: "tool", ReleaseTag: "nightly"}); err != nil || cached.ReleaseTag != second.ReleaseTag {
+		t.Errorf("FindCachedDependency(nightly) = %q, %v, want %q", cached.ReleaseTag, err, second.ReleaseTag)
+	}
+}
@@ -13,6 +13,8 @@ type Dependency struct {
 	Repo       string
 	ReleaseTag string
 	AssetName  string
+	// Package is the registry recipe the dependency was resolved from, if any.
+	Package *Package
 }
 
 func (dep Dependency) String() string {
@@ -31,7 +33,7 @@ func (dep Dependency) String() string {
 }
 
 // RegexpDependency is used to parse dependencies from raw strings.
-var RegexpDependency = regexp.MustCompile(`^((?P<owner>[^/]+)/)?(?P<repo>[a-zA-Z-_.]+)(@(?P<tag>[^:]*))?(:(?P<assets>.*))?$`)
+var RegexpDependency = regexp.MustCompile(`^((?P<owner>[^/]+)/)?(?P<repo>[a-zA-Z0-9-_.]+)(@(?P<tag>[^:]*))?(:(?P<assets>.*))?$`)
 
 // ConvertDependenciesStrings parses raw strings with [RegexpDependency].
 func ConvertDependenciesStrings(s ...string) ([]Dependency, error) {
@@ -0,0 +1,326 @@
+package gpm
+
+import (
+	"context"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+)
+
+// Checks of [GPM.Doctor].
+const (
+	CheckPath            = "path"
+	CheckShadowed        = "shadowed"
+	CheckDanglingLink    = "dangling-link"
+	CheckNotExecutable   = "not-executable"
+	CheckWrongPlatform   = "wrong-platform"
+	CheckMissingMetadata = "missing-metadata"
+	CheckPermissions     = "permissions"
+)
+
+// Problem is an issue of the installation found by [GPM.Doctor].
+type Problem struct {
+	Check   string `json:"check"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	// Fixable is set when [GPM.Doctor] can repair the problem.
+	Fixable bool `json:"fixable"`
+	Fixed   bool `json:"fixed"`
+	fix     func() error
+}
+
+// Doctor checks that the bin dir is on the PATH and that its executables aren't shadowed by earlier PATH
+// directories, that links of known bin dirs point to existing executables of the current platform, that
+// store entries have metadata, and that the store and bin dir are writable. When fix is set, the problems
+// that can be are repaired.
+func (gpm GPM) Doctor(ctx context.Context, fix bool) ([]Problem, error) {
+	binPath, err := gpm.GetBinPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bin path: %w", err)
+	}
+	storePath, err := gpm.GetStorePath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get store path: %w", err)
+	}
+	var problems []Problem
+
+	for _, dir := range []string{storePath, binPath} {
+		if problem, ok := checkWritableDir(dir); ok {
+			problems = append(problems, problem)
+		}
+	}
+	problems = append(problems, checkPATH(binPath, os.Getenv("PATH"))...)
+
+	binPaths, err := gpm.KnownBinPaths()
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	checked := map[string]bool{}
+	for _, dir := range append([]string{binPath}, binPaths...) {
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		problems = append(problems, gpm.checkLinks(dir, checked)...)
+	}
+
+	entries, err := gpm.ListStoreEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if _, err := os.Stat(EntryMetadataPath(entry.Path)); errors.Is(err, os.ErrNotExist) {
+			// Not fixable: the digest of the asset can't be recovered from the extracted files.
+			problems = append(problems, Problem{
+				Check:   CheckMissingMetadata,
+				Path:    entry.Path,
+				Message: fmt.Sprintf("%s has no metadata, it was installed by an older gpm or its metadata was removed", entry.Dependency),
+			})
+		}
+	}
+
+	if fix {
+		for i, problem := range problems {
+			if problem.fix == nil {
+				continue
+			}
+			if err := problem.fix(); err != nil {
+				problems[i].Message += fmt.Sprintf(" (fix failed: %s)", err.Error())
+				continue
+			}
+			problems[i].Fixed = true
+		}
+	}
+	return problems, nil
+}
+
+// Modes of access(2), which the syscall package doesn't define.
+const (
+	accessWrite   = 0x2
+	accessExecute = 0x1
+)
+
+// checkWritableDir reports a problem if dir is missing or not writable by the current user.
+func checkWritableDir(dir string) (Problem, bool) {
+	fileInfo, err := os.Stat(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return Problem{
+			Check:   CheckPermissions,
+			Path:    dir,
+			Message: "directory does not exist",
+			Fixable: true,
+			fix:     func() error { return os.MkdirAll(dir, 0755) },
+		}, true
+	}
+	if err != nil {
+		return Problem{Check: CheckPermissions, Path: dir, Message: err.Error()}, true
+	}
+	if !fileInfo.IsDir() {
+		return Problem{Check: CheckPermissions, Path: dir, Message: "not a directory"}, true
+	}
+	if err := syscall.Access(dir, accessWrite|accessExecute); err != nil {
+		return Problem{
+			Check:   CheckPermissions,
+			Path:    dir,
+			Message: fmt.Sprintf("directory is not writable: %s", err.Error()),
+			Fixable: true,
+			fix:     func() error { return os.Chmod(dir, fileInfo.Mode().Perm()|0700) },
+		}, true
+	}
+	return Problem{}, false
+}
+
+// checkPATH reports a problem if binPath is not in pathEnv, or for each executable of binPath shadowed
+// by an executable of the same name in a directory before binPath in pathEnv.
+func checkPATH(binPath, pathEnv string) []Problem {
+	dirs := filepath.SplitList(pathEnv)
+	index := -1
+	for i, dir := range dirs {
+		if samePath(dir, binPath) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return []Problem{{
+			Check:   CheckPath,
+			Path:    binPath,
+			Message: "bin dir is not in PATH, add it in your shell profile or use gpm env",
+		}}
+	}
+	dirEntries, err := os.ReadDir(binPath)
+	if err != nil {
+		return nil
+	}
+	var problems []Problem
+	for _, dirEntry := range dirEntries {
+		for _, dir := range dirs[:index] {
+			if dir == "" {
+				continue
+			}
+			shadow := filepath.Join(dir, dirEntry.Name())
+			if fileInfo, err := os.Stat(shadow); err == nil && fileInfo.Mode().IsRegular() && fileInfo.Mode()&0111 != 0 {
+				problems = append(problems, Problem{
+					Check:   CheckShadowed,
+					Path:    filepath.Join(binPath, dirEntry.Name()),
+					Message: fmt.Sprintf("shadowed by %q, which comes first in PATH", shadow),
+				})
+				break
+			}
+		}
+	}
+	return problems
+}
+
+func samePath(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	realA, errA := filepath.EvalSymlinks(a)
+	realB, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && realA == realB
+}
+
+// checkLinks reports links of binPath to the store whose target is missing, not executable or built
+// for another platform. Targets are added to checked, and only checked once.
+func (gpm GPM) checkLinks(binPath string, checked map[string]bool) []Problem {
+	dirEntries, err := os.ReadDir(binPath)
+	if err != nil {
+		return nil
+	}
+	var problems []Problem
+	for _, dirEntry := range dirEntries {
+		symLinkPath := filepath.Join(binPath, dirEntry.Name())
+		target, err := readLink(symLinkPath)
+		if err != nil {
+			continue
+		}
+		dep, _, ok := gpm.StoreDependency(target)
+		if !ok {
+			continue
+		}
+		fileInfo, err := os.Stat(target)
+		if err != nil {
+			problems = append(problems, Problem{
+				Check:   CheckDanglingLink,
+				Path:    symLinkPath,
+				Message: fmt.Sprintf("points to %q of %s, which no longer exists", target, dep),
+				Fixable: true,
+				fix: func() error {
+					if _, err := os.Stat(target); err == nil {
+						return nil
+					}
+					return os.Remove(symLinkPath)
+				},
+			})
+			continue
+		}
+		if checked[target] {
+			continue
+		}
+		checked[target] = true
+		if fileInfo.Mode()&0100 == 0 {
+			problems = append(problems, Problem{
+				Check:   CheckNotExecutable,
+				Path:    target,
+				Message: fmt.Sprintf("linked from %q but not executable", symLinkPath),
+				Fixable: true,
+				fix:     func() error { return os.Chmod(target, fileInfo.Mode().Perm()|0111) },
+			})
+		}
+		if platforms := ExecutablePlatforms(target); len(platforms) > 0 {
+			current := CurrentPlatform()
+			compatible := false
+			for _, platform := range platforms {
+				compatible = compatible || platform == current
+			}
+			if !compatible {
+				names := make([]string, 0, len(platforms))
+				for _, platform := range platforms {
+					names = append(names, platform.String())
+				}
+				sort.Strings(names)
+				problems = append(problems, Problem{
+					Check:   CheckWrongPlatform,
+					Path:    target,
+					Message: fmt.Sprintf("built for %v, not %s: install another asset of %s", names, current, dep),
+				})
+			}
+		}
+	}
+	return problems
+}
+
+var (
+	elfArchs = map[elf.Machine]string{
+		elf.EM_X86_64:  "amd64",
+		elf.EM_AARCH64: "arm64",
+		elf.EM_386:     "386",
+		elf.EM_ARM:     "arm",
+	}
+	machoArchs = map[macho.Cpu]string{
+		macho.CpuAmd64: "amd64",
+		macho.CpuArm64: "arm64",
+		macho.Cpu386:   "386",
+		macho.CpuArm:   "arm",
+	}
+	peArchs = map[uint16]string{
+		pe.IMAGE_FILE_MACHINE_AMD64: "amd64",
+		pe.IMAGE_FILE_MACHINE_ARM64: "arm64",
+		pe.IMAGE_FILE_MACHINE_I386:  "386",
+		pe.IMAGE_FILE_MACHINE_ARMNT: "arm",
+	}
+)
+
+// ExecutablePlatforms returns the platforms the executable at filePath is built for, from its ELF,
+// Mach-O (possibly universal) or PE header. It returns nil for other files, like scripts. ELF
+// executables are assumed to be built for linux, unless they are branded for FreeBSD.
+func ExecutablePlatforms(filePath string) []Platform {
+	if f, err := elf.Open(filePath); err == nil {
+		defer f.Close()
+		goos := "linux"
+		if f.OSABI == elf.ELFOSABI_FREEBSD {
+			goos = "freebsd"
+		}
+		arch, ok := elfArchs[f.Machine]
+		if !ok {
+			arch = f.Machine.String()
+		}
+		return []Platform{{OS: goos, Arch: arch}}
+	}
+	if f, err := macho.Open(filePath); err == nil {
+		defer f.Close()
+		return []Platform{{OS: "darwin", Arch: machoArch(f.Cpu)}}
+	}
+	if f, err := macho.OpenFat(filePath); err == nil {
+		defer f.Close()
+		var platforms []Platform
+		for _, arch := range f.Arches {
+			platforms = append(platforms, Platform{OS: "darwin", Arch: machoArch(arch.Cpu)})
+		}
+		return platforms
+	}
+	if f, err := pe.Open(filePath); err == nil {
+		defer f.Close()
+		arch, ok := peArchs[f.Machine]
+		if !ok {
+			arch = fmt.Sprintf("%#x", f.Machine)
+		}
+		return []Platform{{OS: "windows", Arch: arch}}
+	}
+	return nil
+}
+
+func machoArch(cpu macho.Cpu) string {
+	if arch, ok := machoArchs[cpu]; ok {
+		return arch
+	}
+	return cpu.String()
+}
@@ -0,0 +1,116 @@
+package gpm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestDoctor(t *testing.T) {
+	// newFixture returns a GPM whose store and bin dir, in the returned directory, have problems.
+	newFixture := func(t *testing.T) (*GPM, string) {
+		tmp := t.TempDir()
+		storePath := filepath.Join(tmp, "store")
+		binPath := filepath.Join(tmp, "bin")
+		otherBinPath := filepath.Join(tmp, "usr", "bin")
+		entry := filepath.Join(storePath, "github.com", "owner", "tool", "v1.0.0", "tool_linux_amd64")
+		for _, dir := range []string{entry, binPath, otherBinPath} {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		files := map[string]os.FileMode{
+			filepath.Join(entry, "tool"):       0644,
+			filepath.Join(otherBinPath, "cat"): 0755,
+		}
+		for filePath, mode := range files {
+			if err := os.WriteFile(filePath, []byte("#!/bin/sh\n"), mode); err != nil {
+				t.Fatal(err)
+			}
+		}
+		links := map[string]string{
+			"tool": filepath.Join(entry, "tool"),
+			"cat":  filepath.Join(entry, "tool"),
+			"gone": filepath.Join(storePath, "github.com", "owner", "gone", "v1.0.0", "gone", "gone"),
+		}
+		for name, target := range links {
+			if err := os.Symlink(target, filepath.Join(binPath, name)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return NewGPM(WithStorePath(storePath), WithBinPath(binPath)), tmp
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// path are the directories of $PATH, relative to the fixture.
+		path []string
+		fix  bool
+		want []string
+		// wantAfter are the problems found by a second run, after the first.
+		wantAfter []string
+	}{
+		{"Not in PATH", []string{"usr/bin"}, false,
+			[]string{CheckDanglingLink, CheckMissingMetadata, CheckNotExecutable, CheckPath},
+			[]string{CheckDanglingLink, CheckMissingMetadata, CheckNotExecutable, CheckPath}},
+		{"Shadowed", []string{"usr/bin", "bin"}, false,
+			[]string{CheckDanglingLink, CheckMissingMetadata, CheckNotExecutable, CheckShadowed},
+			[]string{CheckDanglingLink, CheckMissingMetadata, CheckNotExecutable, CheckShadowed}},
+		{"Fix", []string{"bin"}, true,
+			[]string{CheckDanglingLink, CheckMissingMetadata, CheckNotExecutable},
+			[]string{CheckMissingMetadata}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gpm, tmp := newFixture(t)
+			var pathEnv []string
+			for _, dir := range tt.path {
+				pathEnv = append(pathEnv, filepath.Join(tmp, dir))
+			}
+			t.Setenv("PATH", strings.Join(pathEnv, string(filepath.ListSeparator)))
+			problems, err := gpm.Doctor(ctx, tt.fix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var checks []string
+			for _, problem := range problems {
+				checks = append(checks, problem.Check)
+				if tt.fix && problem.Fixable && !problem.Fixed {
+					t.Errorf("Doctor() did not fix %+v", problem)
+				}
+				if problem.Check == CheckMissingMetadata && problem.Fixable {
+					t.Errorf("Doctor() reported missing metadata as fixable")
+				}
+			}
+			sort.Strings(checks)
+			if !reflect.DeepEqual(checks, tt.want) {
+				t.Errorf("Doctor() found %v, want %v", checks, tt.want)
+			}
+
+			if problems, err = gpm.Doctor(ctx, false); err != nil {
+				t.Fatal(err)
+			}
+			checks = nil
+			for _, problem := range problems {
+				checks = append(checks, problem.Check)
+			}
+			sort.Strings(checks)
+			if !reflect.DeepEqual(checks, tt.wantAfter) {
+				t.Errorf("Doctor() found %v after the first run, want %v", checks, tt.wantAfter)
+			}
+		})
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ExecutablePlatforms(executable); !reflect.DeepEqual(got, []Platform{CurrentPlatform()}) {
+		t.Errorf("ExecutablePlatforms() = %v, want %v", got, []Platform{CurrentPlatform()})
+	}
+}
@@ -0,0 +1,304 @@
+package gpm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-getter/v2"
+)
+
+// GetStagingPath returns the directory where assets are downloaded before being extracted into the store.
+// Partial downloads are kept there to be resumed.
+func (gpm GPM) GetStagingPath() (string, error) {
+	storePath, err := gpm.GetStorePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(storePath, ".staging"), nil
+}
+
+// partialDownload is saved next to a partially downloaded file to resume it with a conditional range request.
+type partialDownload struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	// Source is the URL that served the download, after redirects and mirrors.
+	Source string `json:"source,omitempty"`
+	// Size is the expected size of the complete file, if known.
+	Size int64 `json:"size,omitempty"`
+}
+
+// Download fetches url into the file at dst. If dst already holds the beginning of the same content,
+// only the remaining bytes are requested with Range and If-Range headers. If the server answers that
+// the range starts at the end of the file, the partial file is kept only if its size is the expected
+// one, otherwise the download restarts from zero. The progressTracker receives the size already
+// downloaded as current size.
+func (gpm GPM) Download(ctx context.Context, url, dst string, progressTracker getter.ProgressTracker) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %q: %w", filepath.Dir(dst), err)
+	}
+	metaPath := dst + ".json"
+
+	var partial partialDownload
+	var offset int64
+	if data, err := os.ReadFile(metaPath); err == nil && json.Unmarshal(data, &partial) == nil && partial.URL == url {
+		if fileInfo, err := os.Stat(dst); err == nil {
+			offset = fileInfo.Size()
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if validator := partial.validator(); offset > 0 && validator != "" {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", validator)
+	}
+	res, err := gpm.HTTPClient().Do(req)
+	if _, until, ok := gpm.retryPolicy.retryReason(res, err); ok && ctx.Err() == nil {
+		if err != nil {
+			return retryableError{error: fmt.Errorf("failed to download %q: %w", url, err)}
+		}
+		res.Body.Close()
+		return retryableError{error: fmt.Errorf("failed to download %q: %s", url, res.Status), until: until}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download %q: %w", url, err)
+	}
+	defer res.Body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE
+	var totalSize int64
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		flags |= os.O_APPEND
+		totalSize = offset + res.ContentLength
+		if size, ok := contentRangeSize(res.Header.Get("Content-Range")); ok {
+			totalSize = size
+		}
+		log.Printf("Resuming download of %q at byte %d", url, offset)
+	case http.StatusOK:
+		flags |= os.O_TRUNC
+		offset = 0
+		totalSize = res.ContentLength
+	case http.StatusRequestedRangeNotSatisfiable:
+		if req.Header.Get("Range") == "" {
+			return fmt.Errorf("failed to download %q: %s", url, res.Status)
+		}
+		size, ok := contentRangeSize(res.Header.Get("Content-Range"))
+		if !ok {
+			size = partial.Size
+		}
+		if size == offset {
+			// The partial file is already complete.
+			return nil
+		}
+		log.Printf("Restarting download of %q: partial file has %d bytes, want %d", url, offset, size)
+		res.Body.Close()
+		if err := RemoveDownload(dst); err != nil {
+			return fmt.Errorf("failed to remove partial download %q: %w", dst, err)
+		}
+		return gpm.Download(ctx, url, dst, progressTracker)
+	default:
+		return fmt.Errorf("failed to download %q: %s", url, res.Status)
+	}
+
+	partial = partialDownload{
+		URL:          url,
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+		Source:       url,
+		Size:         totalSize,
+	}
+	if res.Request != nil {
+		source := *res.Request.URL
+		source.RawQuery, source.User = "", nil
+		partial.Source = source.String()
+	}
+	data, err := json.Marshal(partial)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(metaPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %q: %w", metaPath, err)
+	}
+
+	f, err := os.OpenFile(dst, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %q: %w", dst, err)
+	}
+	defer f.Close()
+
+	var body io.ReadCloser = res.Body
+	if progressTracker != nil {
+		body = progressTracker.TrackProgress(url, offset, totalSize, res.Body)
+		defer body.Close()
+	}
+	written, err := io.Copy(f, body)
+	if err != nil {
+		return retryableError{error: fmt.Errorf("failed to download %q (%d bytes kept to resume): %w", url, offset+written, err)}
+	}
+	if totalSize > 0 && offset+written != totalSize {
+		return retryableError{error: fmt.Errorf("failed to download %q: got %d bytes, want %d", url, offset+written, totalSize)}
+	}
+	return nil
+}
+
+// contentRangeSize returns the complete size from a Content-Range header, like "bytes 0-99/1000" or
+// "bytes */1000".
+func contentRangeSize(contentRange string) (int64, bool) {
+	_, total, ok := strings.Cut(contentRange, "/")
+	if !ok {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(total, 10, 64)
+	return size, err == nil
+}
+
+func (partial partialDownload) validator() string {
+	if partial.ETag != "" && !strings.HasPrefix(partial.ETag, "W/") {
+		return partial.ETag
+	}
+	return partial.LastModified
+}
+
+// downloadSource returns the URL that served the file downloaded at dst by [GPM.Download].
+func downloadSource(dst string) string {
+	var partial partialDownload
+	if data, err := os.ReadFile(dst + ".json"); err == nil && json.Unmarshal(data, &partial) == nil {
+		return partial.Source
+	}
+	return ""
+}
+
+// Fetch returns the body of a successful GET request to url.
+func (gpm GPM) Fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := gpm.HTTPClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %q: %w", url, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %q: %s", url, res.Status)
+	}
+	return io.ReadAll(res.Body)
+}
+
+// RemoveDownload removes a file downloaded with [GPM.Download] and its resume metadata.
+func RemoveDownload(dst string) error {
+	for _, filePath := range []string{dst, dst + ".json"} {
+		if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Extract unpacks the archive at src, detected from assetName, into the directory dst.
+// Files that are not archives are copied into dst as assetName.
+func Extract(src, dst, assetName string) error {
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %q: %w", dst, err)
+	}
+	extension := ""
+	for key := range getter.Decompressors {
+		if strings.HasSuffix(assetName, "."+key) && len(key) > len(extension) {
+			extension = key
+		}
+	}
+	if extension == "" {
+		return copyFile(src, filepath.Join(dst, assetName))
+	}
+	decompressor := getter.Decompressors[extension]
+	switch extension {
+	case "gz", "bz2", "xz", "zst":
+		// Compressed single files.
+		return decompressor.Decompress(filepath.Join(dst, strings.TrimSuffix(assetName, "."+extension)), src, false, 0)
+	default:
+		return decompressor.Decompress(dst, src, true, 0)
+	}
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// VerifyChecksum checks the SHA-256 or SHA-512 digest of the file at filePath against the checksum
+// listed for fileName in checksums, formatted like the output of sha256sum (GNU or BSD style),
+// or holding a single digest.
+func VerifyChecksum(filePath, fileName string, checksums []byte) error {
+	expected := ""
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 1 && expected == "" {
+			expected = fields[0]
+			continue
+		}
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == fileName {
+			expected = fields[0]
+			break
+		}
+		if len(fields) == 4 && fields[1] == "("+fileName+")" && fields[2] == "=" {
+			expected = fields[3]
+			break
+		}
+	}
+	if expected == "" {
+		return fmt.Errorf("no checksum found for %q", fileName)
+	}
+	var h hash.Hash
+	switch len(expected) {
+	case sha256.Size * 2:
+		h = sha256.New()
+	case sha512.Size * 2:
+		h = sha512.New()
+	default:
+		return fmt.Errorf("unsupported checksum %q for %q", expected, fileName)
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if actual := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("checksum mismatch for %q: got %s, want %s", fileName, actual, expected)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package gpm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownload_Resume(t *testing.T) {
+	content := bytes.Repeat([]byte("gpm"), 1000)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "asset", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	dst := filepath.Join(t.TempDir(), "asset")
+	if err := os.WriteFile(dst, content[:1000], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst+".json", []byte(`{"url":"`+server.URL+`","etag":"\"v1\""}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	gpm := NewGPM(WithHTTPClient(server.Client()))
+	if err := gpm.Download(context.Background(), server.URL, dst, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=1000-" {
+		t.Errorf("Download() sent ranges %q, want [bytes=1000-]", ranges)
+	}
+	if got, _ := os.ReadFile(dst); !bytes.Equal(got, content) {
+		t.Errorf("Download() wrote %d bytes, want %d", len(got), len(content))
+	}
+}
+
+func TestDownload_RangeNotSatisfiable(t *testing.T) {
+	content := bytes.Repeat([]byte("gpm"), 1000)
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("ETag", `"v1"`)
+		if r.URL.Path == "/no-content-range" && r.Header.Get("Range") != "" {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		http.ServeContent(w, r, "asset", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		path         string
+		partial      []byte
+		sidecarSize  int
+		wantRequests int
+	}{
+		{"Complete", "/", content, 0, 1},
+		{"Larger than the content", "/", append(content, "extra"...), 0, 2},
+		{"Expected size in the sidecar", "/no-content-range", content, len(content), 1},
+		{"Unknown size", "/no-content-range", content, 0, 2},
+		{"Other size in the sidecar", "/no-content-range", content[:1500], 1500 + 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := server.URL + tt.path
+			dst := filepath.Join(t.TempDir(), "asset")
+			if err := os.WriteFile(dst, tt.partial, 0644); err != nil {
+				t.Fatal(err)
+			}
+			sidecar := fmt.Sprintf(`{"url":%q,"etag":"\"v1\"","size":%d}`, url, tt.sidecarSize)
+			if err := os.WriteFile(dst+".json", []byte(sidecar), 0644); err != nil {
+				t.Fatal(err)
+			}
+			requests = 0
+			gpm := NewGPM(WithHTTPClient(server.Client()))
+			if err := gpm.Download(context.Background(), url, dst, nil); err != nil {
+				t.Fatal(err)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("Download() sent %d requests, want %d", requests, tt.wantRequests)
+			}
+			if got, _ := os.ReadFile(dst); !bytes.Equal(got, content) {
+				t.Errorf("Download() left %d bytes, want %d", len(got), len(content))
+			}
+		})
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "asset")
+	if err := os.WriteFile(filePath, []byte("gpm"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	const sum = "bc0206d1dbd4b26138cb8dd406359c5a1cb4013d31ce50714a718b8ef9b3b32c"
+	const otherSum = "19b8da4f8a7d1b3e7e5d2af6e3bbd1553b0f5c0a6f2b1d1ef5b7d84adbc35a67"
+	tests := []struct {
+		name      string
+		checksums string
+		wantErr   string
+	}{
+		{"Single digest", sum + "\n", ""},
+		{"GNU style", otherSum + "  other\n" + sum + " *asset\n", ""},
+		{"BSD style", "SHA256 (asset) = " + sum + "\n", ""},
+		{"Mismatch", otherSum + "  asset\n", "mismatch"},
+		{"Missing", sum + "  other\n", "no checksum"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyChecksum(filePath, "asset", []byte(tt.checksums))
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("VerifyChecksum() = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("VerifyChecksum() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package gpm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StoreEntry is a downloaded asset of the store.
+type StoreEntry struct {
+	Dependency
+	Path string `json:"path"`
+	// Size is the total size of the files of the entry in bytes.
+	Size int64 `json:"size"`
+	// ModTime is when the entry was installed into the store.
+	ModTime time.Time `json:"mod_time"`
+}
+
+// ListStoreEntries returns the downloaded assets of the store with their size.
+func (gpm GPM) ListStoreEntries(ctx context.Context) ([]StoreEntry, error) {
+	storePath, err := gpm.GetStorePath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get store path: %w", err)
+	}
+	deps, err := gpm.ListDownloadedDependencies(ctx)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]StoreEntry, 0, len(deps))
+	for _, dep := range deps {
+		entryPath := filepath.Join(storePath, "github.com", dep.Owner, dep.Repo, dep.ReleaseTag, dep.AssetName)
+		fileInfo, err := os.Stat(entryPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %q: %w", entryPath, err)
+		}
+		size, err := dirSize(entryPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute size of %q: %w", entryPath, err)
+		}
+		entries = append(entries, StoreEntry{Dependency: dep, Path: entryPath, Size: size, ModTime: fileInfo.ModTime()})
+	}
+	return entries, nil
+}
+
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, dirEntry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if dirEntry.Type().IsRegular() {
+			fileInfo, err := dirEntry.Info()
+			if err != nil {
+				return err
+			}
+			size += fileInfo.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// GetBinPathsPath returns the path of the file listing the bin dirs gpm linked executables into.
+func (gpm GPM) GetBinPathsPath() (string, error) {
+	storePath, err := gpm.GetStorePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(storePath, "bin-dirs.json"), nil
+}
+
+// KnownBinPaths returns the bin dirs gpm linked executables into, including project bin dirs.
+func (gpm GPM) KnownBinPaths() ([]string, error) {
+	binPathsPath, err := gpm.GetBinPathsPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bin dirs path: %w", err)
+	}
+	var binPaths []string
+	data, err := os.ReadFile(binPathsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", binPathsPath, err)
+	}
+	if err := json.Unmarshal(data, &binPaths); err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", binPathsPath, err)
+	}
+	return binPaths, nil
+}
+
+// recordBinPath adds binPath to the known bin dirs. The links lock must be held.
+func (gpm GPM) recordBinPath(binPath string) error {
+	binPaths, err := gpm.KnownBinPaths()
+	if err != nil {
+		return err
+	}
+	for _, known := range binPaths {
+		if known == binPath {
+			return nil
+		}
+	}
+	binPathsPath, err := gpm.GetBinPathsPath()
+	if err != nil {
+		return fmt.Errorf("failed to get bin dirs path: %w", err)
+	}
+	data, err := json.MarshalIndent(append(binPaths, binPath), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(binPathsPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %q: %w", filepath.Dir(binPathsPath), err)
+	}
+	if err := writeFileAtomic(binPathsPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %q: %w", binPathsPath, err)
+	}
+	return nil
+}
+
+// GCPolicy selects the store entries removed by [GPM.GC]. Entries linked from a known bin dir or
+// required by a manifest or its lock file are always kept.
+type GCPolicy struct {
+	// KeepLast keeps the KeepLast most recently installed versions of each repository.
+	KeepLast int
+	// OlderThan only removes versions installed more than OlderThan ago. Zero removes versions of any age.
+	OlderThan time.Duration
+	// Manifests are paths of manifests whose dependencies are kept, in addition to the manifests of the
+	// projects of known bin dirs.
+	Manifests []string
+	// DryRun only reports the entries that would be removed.
+	DryRun bool
+}
+
+// GC removes the store entries that are not referenced by a link, a manifest, a lock file or the policy,
+// and returns them. Entries locked by another install, or by gpm run, are skipped.
+func (gpm GPM) GC(ctx context.Context, policy GCPolicy) ([]StoreEntry, error) {
+	unlock, err := gpm.lockLinks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	entries, err := gpm.ListStoreEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	referenced, err := gpm.referencedEntries(ctx, entries, policy.Manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	// Versions of each repository, the most recently installed first.
+	versionTimes := map[string]time.Time{}
+	repoVersions := map[string][]string{}
+	for _, entry := range entries {
+		repo := entry.Owner + "/" + entry.Repo
+		version := repo + "@" + entry.ReleaseTag
+		if _, ok := versionTimes[version]; !ok {
+			repoVersions[repo] = append(repoVersions[repo], version)
+		}
+		if entry.ModTime.After(versionTimes[version]) {
+			versionTimes[version] = entry.ModTime
+		}
+	}
+	kept := map[string]bool{}
+	for _, versions := range repoVersions {
+		sort.SliceStable(versions, func(i, j int) bool { return versionTimes[versions[i]].After(versionTimes[versions[j]]) })
+		for i := 0; i < policy.KeepLast && i < len(versions); i++ {
+			kept[versions[i]] = true
+		}
+	}
+
+	storePath, err := gpm.GetStorePath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get store path: %w", err)
+	}
+	var removed []StoreEntry
+	for _, entry := range entries {
+		version := entry.Owner + "/" + entry.Repo + "@" + entry.ReleaseTag
+		if referenced[entry.Path] || kept[version] {
+			continue
+		}
+		if policy.OlderThan > 0 && time.Since(versionTimes[version]) < policy.OlderThan {
+			continue
+		}
+		if !policy.DryRun {
+			// Entries being installed, linked or run hold their lock until they are linked or run.
+			unlock, ok, err := gpm.tryLockAsset(entry.Dependency)
+			if err != nil {
+				return removed, err
+			}
+			if !ok {
+				log.Printf("Skipping %q, in use by another gpm", entry.Path)
+				continue
+			}
+			err = os.RemoveAll(entry.Path)
+			if err == nil {
+				if err := os.Remove(EntryMetadataPath(entry.Path)); err != nil && !errors.Is(err, os.ErrNotExist) {
+					log.Printf("Failed to remove metadata of %q: %s", entry.Path, err.Error())
+				}
+				removeEmptyParents(filepath.Dir(entry.Path), storePath)
+			}
+			unlock()
+			if err != nil {
+				return removed, fmt.Errorf("failed to remove %q: %w", entry.Path, err)
+			}
+			log.Printf("Removed %q", entry.Path)
+		}
+		removed = append(removed, entry)
+	}
+	return removed, nil
+}
+
+// referencedEntries returns the paths of the entries linked from known bin dirs or required by manifests
+// and their lock files. Manifest dependencies reference the entries they resolve to. See [cachedEntries].
+func (gpm GPM) referencedEntries(ctx context.Context, entries []StoreEntry, manifests []string) (map[string]bool, error) {
+	referenced := map[string]bool{}
+
+	binPaths, err := gpm.KnownBinPaths()
+	if err != nil {
+		return nil, err
+	}
+	if binPath, err := gpm.GetBinPath(); err == nil {
+		binPaths = append(binPaths, binPath)
+	}
+	for _, binPath := range binPaths {
+		dirEntries, err := os.ReadDir(binPath)
+		if err != nil {
+			continue
+		}
+		for _, dirEntry := range dirEntries {
+			if target, err := readLink(filepath.Join(binPath, dirEntry.Name())); err == nil {
+				if _, dir, ok := gpm.StoreDependency(target); ok {
+					referenced[dir] = true
+				}
+			}
+		}
+		if filepath.Base(binPath) == "bin" && filepath.Base(filepath.Dir(binPath)) == ".gpm" {
+			manifests = append(manifests, filepath.Join(filepath.Dir(filepath.Dir(binPath)), ManifestFileName))
+		}
+	}
+
+	for _, manifestPath := range manifests {
+		manifest, err := LoadManifest(manifestPath)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		deps, err := gpm.ConvertDependenciesStrings(ctx, manifest.Dependencies...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse dependencies of %q: %w", manifestPath, err)
+		}
+		lockFile, err := LoadLockFile(LockFilePath(manifestPath))
+		if err != nil {
+			return nil, err
+		}
+		for _, locked := range lockFile.Dependencies {
+			deps = append(deps, Dependency{Owner: locked.Owner, Repo: locked.Repo, ReleaseTag: locked.Tag})
+		}
+		for _, dep := range deps {
+			cached, err := cachedEntries(entries, dep)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve %q of %q: %w", dep, manifestPath, err)
+			}
+			for _, entry := range cached {
+				referenced[entry.Path] = true
+			}
+		}
+	}
+	return referenced, nil
+}
+
+// cachedEntries returns the entries dep resolves to in the store, like [GPM.FindCachedDependency]: the most
+// recently installed one for a channel, and for a release tag, the entries of this tag along with the most
+// recently installed entry stamped with it, which is the one a moving tag resolves to. A constraint
+// resolves to the highest matching version in the store.
+func cachedEntries(entries []StoreEntry, dep Dependency) ([]StoreEntry, error) {
+	if IsConstraint(dep.ReleaseTag) {
+		constraint, err := ParseConstraint(dep.ReleaseTag)
+		if err != nil {
+			return nil, err
+		}
+		var tags []string
+		for _, entry := range entries {
+			if entry.isOf(dep) {
+				tag, _ := SplitStampedTag(entry.ReleaseTag)
+				tags = append(tags, tag)
+			}
+		}
+		highest, ok := constraint.MatchTag(tags)
+		if !ok {
+			return nil, nil
+		}
+		dep.ReleaseTag = highest
+	}
+	_, stamp := SplitStampedTag(dep.ReleaseTag)
+	var matching []StoreEntry
+	var latest *StoreEntry
+	for i, entry := range entries {
+		if !entry.isOf(dep) {
+			continue
+		}
+		entryTag := entry.ReleaseTag
+		if stamp == "" {
+			entryTag, _ = SplitStampedTag(entryTag)
+		}
+		switch {
+		case IsChannel(dep.ReleaseTag):
+		case entryTag == dep.ReleaseTag || entryTag == "v"+dep.ReleaseTag:
+			if entry.ReleaseTag == entryTag {
+				matching = append(matching, entry)
+			}
+		default:
+			continue
+		}
+		if latest == nil || entry.ModTime.After(latest.ModTime) {
+			latest = &entries[i]
+		}
+	}
+	if latest != nil {
+		matching = append(matching, *latest)
+	}
+	return matching, nil
+}
+
+// isOf reports whether entry is an asset of the repository of dep, and the asset of dep if it names one.
+func (entry StoreEntry) isOf(dep Dependency) bool {
+	return strings.EqualFold(entry.Repo, dep.Repo) && (dep.Owner == "" || strings.EqualFold(entry.Owner, dep.Owner)) &&
+		(dep.AssetName == "" || entry.AssetName == dep.AssetName)
+}
@@ -0,0 +1,94 @@
+package gpm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestGC(t *testing.T) {
+	tmp := t.TempDir()
+	storePath := filepath.Join(tmp, "store")
+	gpm := NewGPM(WithStorePath(storePath), WithBinPath(filepath.Join(tmp, "bin")))
+	ctx := context.Background()
+	now := time.Now()
+	for i, version := range []string{"linked/v1", "linked/v2", "linked/v3", "pinned/v1", "pinned/v2", "latest/v1", "latest/v2", "stable/v1", "stable/v2", "nightly/nightly+0123456789ab", "nightly/nightly+ba9876543210", "semver/v1.3.0", "semver/v1.4.0", "semver/v1.5.0", "semver/v2.0.0"} {
+		dir := filepath.Join(storePath, "github.com", "owner", filepath.Dir(version), filepath.Base(version), "asset")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.Dir(version)), []byte("bin"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		// Versions are installed in order, one day apart.
+		modTime := now.Add(time.Duration(i-10) * 24 * time.Hour)
+		if err := os.Chtimes(dir, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := gpm.Use(ctx, Dependency{Owner: "owner", Repo: "linked", ReleaseTag: "v1"}); err != nil {
+		t.Fatal(err)
+	}
+	manifestPath := filepath.Join(tmp, ManifestFileName)
+	if err := (Manifest{Dependencies: []string{"owner/pinned@v1", "owner/latest", "owner/stable@stable", "owner/nightly@nightly", "owner/semver@^1.4"}}).Save(manifestPath); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		policy GCPolicy
+		want   []string
+	}{
+		{"Manifest", GCPolicy{Manifests: []string{manifestPath}}, []string{"owner/latest@v1:asset", "owner/linked@v2:asset", "owner/linked@v3:asset", "owner/nightly@nightly+0123456789ab:asset", "owner/pinned@v2:asset", "owner/semver@v1.3.0:asset", "owner/semver@v1.4.0:asset", "owner/semver@v2.0.0:asset", "owner/stable@v1:asset"}},
+		{"Older than", GCPolicy{OlderThan: 17 * 12 * time.Hour, Manifests: []string{manifestPath}}, []string{"owner/linked@v2:asset"}},
+		{"Keep last", GCPolicy{KeepLast: 1, Manifests: []string{manifestPath}}, []string{"owner/latest@v1:asset", "owner/linked@v2:asset", "owner/nightly@nightly+0123456789ab:asset", "owner/semver@v1.3.0:asset", "owner/semver@v1.4.0:asset", "owner/stable@v1:asset"}},
+		{"No manifest", GCPolicy{}, []string{"owner/latest@v1:asset", "owner/latest@v2:asset", "owner/linked@v2:asset", "owner/linked@v3:asset", "owner/nightly@nightly+0123456789ab:asset", "owner/nightly@nightly+ba9876543210:asset", "owner/pinned@v1:asset", "owner/pinned@v2:asset", "owner/semver@v1.3.0:asset", "owner/semver@v1.4.0:asset", "owner/semver@v1.5.0:asset", "owner/semver@v2.0.0:asset", "owner/stable@v1:asset", "owner/stable@v2:asset"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.policy.DryRun = true
+			removed, err := gpm.GC(ctx, tt.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, entry := range removed {
+				got = append(got, entry.Dependency.String())
+			}
+			sort.Strings(got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("GC() removed %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("GC() removed %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	// An entry locked by an install is kept until it is released.
+	unlock, err := gpm.lockAsset(ctx, Dependency{Owner: "owner", Repo: "linked", ReleaseTag: "v3", AssetName: "asset"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []int{7, 6} {
+		if _, err := gpm.GC(ctx, GCPolicy{Manifests: []string{manifestPath}}); err != nil {
+			t.Fatal(err)
+		}
+		entries, err := gpm.ListStoreEntries(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != want {
+			t.Errorf("ListStoreEntries() after GC() = %d entries, want %d", len(entries), want)
+		}
+		unlock()
+	}
+	if _, err := os.Stat(filepath.Join(storePath, "github.com", "owner", "linked", "v2")); !os.IsNotExist(err) {
+		t.Errorf("GC() left the empty tag directory, stat error = %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package gpm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v47/github"
+)
+
+// SearchRepositories queries Github for repositories matching query.
+func (gpm GPM) SearchRepositories(ctx context.Context, query string) ([]*github.Repository, error) {
+	result, _, err := gpm.GithubClient().Search.Repositories(ctx, query, &github.SearchOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search repositories %q: %w", query, err)
+	}
+	return result.Repositories, nil
+}
+
+// ListReleases returns the most recent releases of a Github repository.
+func (gpm GPM) ListReleases(ctx context.Context, owner, repo string) ([]*github.RepositoryRelease, error) {
+	releases, _, err := gpm.GithubClient().Repositories.ListReleases(ctx, owner, repo, &github.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list releases of %s/%s: %w", owner, repo, err)
+	}
+	return releases, nil
+}
+
+// ListAllReleases returns all the releases of a Github repository, most recent first.
+func (gpm GPM) ListAllReleases(ctx context.Context, owner, repo string) ([]*github.RepositoryRelease, error) {
+	var releases []*github.RepositoryRelease
+	err := gpm.forEachRelease(ctx, owner, repo, func(release *github.RepositoryRelease) bool {
+		releases = append(releases, release)
+		return true
+	})
+	return releases, err
+}
+
+// forEachRelease calls fn with the releases of a Github repository, most recent first, paging through all
+// of them until fn returns false.
+func (gpm GPM) forEachRelease(ctx context.Context, owner, repo string, fn func(*github.RepositoryRelease) bool) error {
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		releases, res, err := gpm.GithubClient().Repositories.ListReleases(ctx, owner, repo, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list releases of %s/%s: %w", owner, repo, err)
+		}
+		for _, release := range releases {
+			if !fn(release) {
+				return nil
+			}
+		}
+		if res.NextPage == 0 {
+			return nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
+// GetRelease returns the release of a Github repository named tag.
+// An empty tag or "latest" returns the latest release marked by Github, and other channels are resolved
+// among the most recent releases (see [IsChannel]). A version constraint returns the release with the
+// highest version satisfying it (see [ParseConstraint]). The stamp of a tag made by [StampTag] is ignored.
+// Versions without the conventional "v" prefix (eg. 1.2.3 for v1.2.3) are also found.
+func (gpm GPM) GetRelease(ctx context.Context, owner, repo, tag string) (*github.RepositoryRelease, error) {
+	tag, _ = SplitStampedTag(tag)
+	if tag == ChannelStable || tag == ChannelPrerelease {
+		return gpm.getChannelRelease(ctx, owner, repo, tag)
+	}
+	if IsConstraint(tag) {
+		return gpm.getConstraintRelease(ctx, owner, repo, tag)
+	}
+	if tag == "" || tag == ChannelLatest {
+		release, _, err := gpm.GithubClient().Repositories.GetLatestRelease(ctx, owner, repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get latest release of %s/%s: %w", owner, repo, err)
+		}
+		return release, nil
+	}
+	release, res, err := gpm.GithubClient().Repositories.GetReleaseByTag(ctx, owner, repo, tag)
+	if err != nil && res != nil && res.StatusCode == http.StatusNotFound && !strings.HasPrefix(tag, "v") {
+		release, _, err = gpm.GithubClient().Repositories.GetReleaseByTag(ctx, owner, repo, "v"+tag)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get release %s/%s@%s: %w", owner, repo, tag, err)
+	}
+	return release, nil
+}
+
+// SearchResult is a repository found by [GPM.Search] along with its latest release.
+type SearchResult struct {
+	FullName         string `json:"full_name"`
+	Description      string `json:"description,omitempty"`
+	Language         string `json:"language,omitempty"`
+	Stars            int    `json:"stars"`
+	LatestRelease    string `json:"latest_release,omitempty"`
+	HasPlatformAsset bool   `json:"has_platform_asset"`
+}
+
+// searchLookups bounds the number of concurrent latest release lookups of [GPM.Search].
+const searchLookups = 4
+
+// Search queries Github for repositories and looks up the latest release of the first limit results
+// to tell whether they provide an asset for platform. Repositories without release are kept, other
+// lookup errors, like rate limits, fail the search.
+func (gpm GPM) Search(ctx context.Context, query string, limit int, platform Platform) ([]SearchResult, error) {
+	repositories, err := gpm.SearchRepositories(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(repositories) > limit {
+		repositories = repositories[:limit]
+	}
+	results := make([]SearchResult, len(repositories))
+	errs := make([]error, len(repositories))
+	sem := make(chan struct{}, searchLookups)
+	var wg sync.WaitGroup
+	for i, repository := range repositories {
+		results[i] = SearchResult{
+			FullName:    repository.GetFullName(),
+			Description: repository.GetDescription(),
+			Language:    repository.GetLanguage(),
+			Stars:       repository.GetStargazersCount(),
+		}
+		wg.Add(1)
+		go func(i int, repository *github.Repository) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			release, res, err := gpm.GithubClient().Repositories.GetLatestRelease(ctx, repository.GetOwner().GetLogin(), repository.GetName())
+			switch {
+			case err == nil:
+				results[i].LatestRelease = release.GetTagName()
+				results[i].HasPlatformAsset = len(PlatformAssets(AssetsNames(release), platform)) > 0
+			case res == nil || res.StatusCode != http.StatusNotFound:
+				errs[i] = fmt.Errorf("failed to get latest release of %s: %w", repository.GetFullName(), err)
+			}
+		}(i, repository)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// AssetsNames returns the names of the assets attached to release.
+func AssetsNames(release *github.RepositoryRelease) []string {
+	names := make([]string, 0, len(release.Assets))
+	for _, asset := range release.Assets {
+		names = append(names, asset.GetName())
+	}
+	return names
+}
+
+// GetRepository returns the metadata of a Github repository, like its description and license.
+func (gpm GPM) GetRepository(ctx context.Context, owner, repo string) (*github.Repository, error) {
+	repository, _, err := gpm.GithubClient().Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository %s/%s: %w", owner, repo, err)
+	}
+	return repository, nil
+}
@@ -0,0 +1,71 @@
+package gpm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	rateLimited := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/repositories":
+			json.NewEncoder(w).Encode(map[string]any{"items": []map[string]any{
+				{"name": "tool", "full_name": "owner/tool", "owner": map[string]string{"login": "owner"}, "stargazers_count": 42},
+				{"name": "norelease", "full_name": "owner/norelease", "owner": map[string]string{"login": "owner"}},
+			}})
+		case "/repos/owner/tool/releases/latest":
+			if rateLimited {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"message": "API rate limit exceeded"}`))
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{
+				"tag_name": "v1.0.0",
+				"assets":   []map[string]string{{"name": "tool_" + CurrentPlatform().OS + "_" + CurrentPlatform().Arch}},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	gpm := NewGPM(
+		WithStorePath(t.TempDir()),
+		WithHTTPClient(&http.Client{Transport: serverTransport{server}}),
+		WithHTTPCache(false),
+		WithRetryPolicy(RetryPolicy{Attempts: 1}),
+	)
+	tests := []struct {
+		name        string
+		rateLimited bool
+		want        []SearchResult
+		wantErr     bool
+	}{
+		{"Found", false, []SearchResult{
+			{FullName: "owner/tool", Stars: 42, LatestRelease: "v1.0.0", HasPlatformAsset: true},
+			{FullName: "owner/norelease"},
+		}, false},
+		{"Rate limited", true, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rateLimited = tt.rateLimited
+			results, err := gpm.Search(context.Background(), "tool", 0, CurrentPlatform())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Search() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(results) != len(tt.want) {
+				t.Fatalf("Search() = %+v, want %+v", results, tt.want)
+			}
+			for i := range results {
+				if results[i] != tt.want[i] {
+					t.Errorf("Search()[%d] = %+v, want %+v", i, results[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
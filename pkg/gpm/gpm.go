@@ -1,25 +1,53 @@
 package gpm
 
 import (
+	"net/http"
 	"os"
 	"path/filepath"
+	"time"
+
+	"github.com/google/go-github/v47/github"
 )
 
 // GPM holds the common configurations to manage [Dependency].
 type GPM struct {
-	homePath  string
-	storePath string
-	binPath   string
+	homePath     string
+	storePath    string
+	binPath      string
+	configPath   string
+	httpClient   *http.Client
+	ownerChooser OwnerChooser
+	registries   []string
+	retryPolicy  RetryPolicy
+	scheduler    *Scheduler
+	lockTimeout  time.Duration
+	offline      bool
+	httpCache    bool
+	cacheTTLs    []CacheTTL
+	mirrors      MirrorsConfig
+	shims        bool
+	noHooks      bool
+	remoteHooks  bool
 }
 
 func NewGPM(opts ...GPMOption) *GPM {
-	gpm := &GPM{}
+	gpm := &GPM{
+		retryPolicy: DefaultRetryPolicy,
+		lockTimeout: DefaultLockTimeout,
+		httpCache:   true,
+		cacheTTLs:   DefaultCacheTTLs,
+	}
 
 	// Apply all options to the program.
 	for _, opt := range opts {
 		opt(gpm)
 	}
 
+	gpm.httpClient = newHTTPClient(gpm.httpClient, gpm.retryPolicy, gpm.mirrors)
+	if gpm.offline {
+		gpm.httpClient.Transport = offlineTransport{}
+	}
+
 	return gpm
 }
 
@@ -80,5 +108,53 @@ func (gpm GPM) GetBinPath() (string, error) {
 		return "", err
 	}
 	return filepath.Join(homePath, ".local", "bin"), nil
+}
+
+// WithConfigPath sets the directory holding user configurations, like registries.
+// Defaults to ~/.config/gpm.
+func WithConfigPath(configPath string) GPMOption {
+	return func(gpm *GPM) {
+		gpm.configPath = configPath
+	}
+}
+
+// GetConfigPath returns the config directory. See [WithConfigPath].
+func (gpm GPM) GetConfigPath() (string, error) {
+	return ConfigPath(gpm.homePath, gpm.configPath)
+}
+
+// ConfigPath returns configPath, or the default config directory in homePath if configPath is empty.
+// An empty homePath is the home directory of the user. See [WithHomePath] and [WithConfigPath].
+func ConfigPath(homePath, configPath string) (string, error) {
+	if configPath != "" {
+		return configPath, nil
+	}
+	homePath, err := GPM{homePath: homePath}.GetHomePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homePath, ".config", "gpm"), nil
+}
+
+// WithHTTPClient sets the HTTP client used to reach Github and registries. Requests are retried
+// according to the [RetryPolicy].
+// Defaults to [http.DefaultClient].
+func WithHTTPClient(httpClient *http.Client) GPMOption {
+	return func(gpm *GPM) {
+		gpm.httpClient = httpClient
+	}
+}
+
+// HTTPClient returns the HTTP client used for all requests. See [WithHTTPClient].
+func (gpm GPM) HTTPClient() *http.Client {
+	if gpm.httpClient != nil {
+		return gpm.httpClient
+	}
+	return http.DefaultClient
+}
 
+// GithubClient returns a Github API client, caching responses unless disabled with [WithHTTPCache].
+// See [WithHTTPClient].
+func (gpm GPM) GithubClient() *github.Client {
+	return github.NewClient(gpm.apiHTTPClient())
 }
@@ -0,0 +1,210 @@
+package gpm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SmokeTest is a command checking that the executables of a package run on this host.
+type SmokeTest struct {
+	// Command is run with sh, after placeholders are replaced. See [Package.Test].
+	Command string `yaml:"command" json:"command"`
+	// Expect is a regular expression the output of Command must match. Only the exit code is checked when empty.
+	Expect string `yaml:"expect,omitempty" json:"expect,omitempty"`
+}
+
+// HookTimeout bounds the run time of a smoke test or post-install hook.
+const HookTimeout = time.Minute
+
+// maxHookOutput bounds the captured output of hooks.
+const maxHookOutput = 64 << 10
+
+// WithHooks enables or disables smoke tests and post-install hooks of packages. They are enabled by default.
+func WithHooks(enabled bool) GPMOption {
+	return func(gpm *GPM) {
+		gpm.noHooks = !enabled
+	}
+}
+
+// WithRemoteHooks enables the smoke tests and post-install hooks of packages from registries at HTTP URLs,
+// which are skipped by default since they run commands from a remote source.
+func WithRemoteHooks(enabled bool) GPMOption {
+	return func(gpm *GPM) {
+		gpm.remoteHooks = enabled
+	}
+}
+
+// hookEnv are the variables of the environment passed to hooks, in addition to HOME, TMPDIR and PATH.
+// Other variables, like GITHUB_TOKEN, are not passed.
+var hookEnv = []string{"LANG", "LC_ALL", "TERM", "TZ", "USER"}
+
+// HookResult is the outcome of a smoke test or post-install hook.
+type HookResult struct {
+	// Command is the command as declared by the package, before placeholders are replaced.
+	Command string
+	// Output holds the standard and error outputs of the command.
+	Output string
+	Err    error
+}
+
+type hookNotifierKey struct{}
+
+// WithHookNotifier returns a context whose installs report the results of smoke tests and hooks to notify.
+func WithHookNotifier(ctx context.Context, notify func(HookResult)) context.Context {
+	return context.WithValue(ctx, hookNotifierKey{}, notify)
+}
+
+func notifyHook(ctx context.Context, result HookResult) {
+	if notify, ok := ctx.Value(hookNotifierKey{}).(func(HookResult)); ok {
+		notify(result)
+	}
+}
+
+// HookError is returned when a smoke test or post-install hook of a dependency fails.
+type HookError struct {
+	Dependency Dependency
+	HookResult
+}
+
+func (err *HookError) Error() string {
+	msg := fmt.Sprintf("%q of %s failed: %s", err.Command, err.Dependency, err.Err.Error())
+	if output := strings.TrimSpace(err.Output); output != "" {
+		msg += "\n" + output
+	}
+	return msg
+}
+
+func (err *HookError) Unwrap() error { return err.Err }
+
+// runHooks runs the smoke test then the post-install hooks of the package of dep, whose executables, by
+// link name, are linked into the bin dir. Commands are run with sh in a temporary directory that is also
+// their HOME, with the bin dir first in PATH and a minimal environment. See [hookEnv]. Hooks of packages
+// from registries at HTTP URLs are skipped unless enabled with [WithRemoteHooks].
+func (gpm GPM) runHooks(ctx context.Context, dep Dependency, executables map[string]string) error {
+	if gpm.noHooks || dep.Package == nil || (dep.Package.Test == nil && len(dep.Package.PostInstall) == 0) {
+		return nil
+	}
+	if isURL(dep.Package.Source) && !gpm.remoteHooks {
+		log.Printf("Skipping hooks of %q from remote registry %q", dep, dep.Package.Source)
+		return nil
+	}
+	binPath, err := gpm.GetBinPath()
+	if err != nil {
+		return fmt.Errorf("failed to get bin path: %w", err)
+	}
+	tmp, err := os.MkdirTemp("", "gpm-hook-")
+	if err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	notifyState(ctx, StateTesting)
+	env := []string{"HOME=" + tmp, "TMPDIR=" + tmp, "PATH=" + binPath + string(os.PathListSeparator) + os.Getenv("PATH")}
+	for _, key := range hookEnv {
+		if value, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+value)
+		}
+	}
+
+	run := func(command string, expect *regexp.Regexp) error {
+		result := HookResult{Command: command}
+		script, err := expandHook(command, binPath, dep, executables)
+		if err == nil {
+			var output []byte
+			output, err = runHook(ctx, script, tmp, env)
+			result.Output = string(output)
+			if err == nil && expect != nil && !expect.Match(output) {
+				err = fmt.Errorf("output does not match %q", expect.String())
+			}
+		}
+		result.Err = err
+		notifyHook(ctx, result)
+		if err != nil {
+			return &HookError{Dependency: dep, HookResult: result}
+		}
+		log.Printf("Hook %q of %q passed", command, dep)
+		return nil
+	}
+	if test := dep.Package.Test; test != nil {
+		var expect *regexp.Regexp
+		if test.Expect != "" {
+			if expect, err = regexp.Compile(test.Expect); err != nil {
+				return fmt.Errorf("invalid expected output of smoke test of %q: %w", dep, err)
+			}
+		}
+		if err := run(test.Command, expect); err != nil {
+			return err
+		}
+	}
+	for _, command := range dep.Package.PostInstall {
+		if err := run(command, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var hookPlaceholderRegexp = regexp.MustCompile(`\{bin(?::([^}]+))?\}|\{bin_dir\}`)
+
+// expandHook replaces the placeholders of command, with shell quoted paths. See [Package.Test].
+func expandHook(command, binPath string, dep Dependency, executables map[string]string) (string, error) {
+	var err error
+	script := hookPlaceholderRegexp.ReplaceAllStringFunc(command, func(placeholder string) string {
+		if placeholder == "{bin_dir}" {
+			return shellQuote(binPath)
+		}
+		name := hookPlaceholderRegexp.FindStringSubmatch(placeholder)[1]
+		if name == "" {
+			if _, ok := executables[dep.Repo]; ok {
+				name = dep.Repo
+			} else if len(executables) == 1 {
+				name = sortedKeys(executables)[0]
+			} else if err == nil {
+				err = fmt.Errorf("{bin} is ambiguous among %d executables, use {bin:NAME}", len(executables))
+			}
+		} else if _, ok := executables[name]; !ok && err == nil {
+			err = fmt.Errorf("no executable named %q", name)
+		}
+		return shellQuote(filepath.Join(binPath, name))
+	})
+	return script, err
+}
+
+// runHook runs script with sh in dir, and returns its combined output, truncated to maxHookOutput bytes.
+func runHook(ctx context.Context, script, dir string, env []string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, HookTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", script)
+	cmd.Dir = dir
+	cmd.Env = env
+	var output limitedBuffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %s", HookTimeout)
+	}
+	return output.Bytes(), err
+}
+
+// limitedBuffer is a [bytes.Buffer] discarding what is written past maxHookOutput bytes.
+type limitedBuffer struct {
+	bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := maxHookOutput - b.Len(); remaining < len(p) {
+		if remaining > 0 {
+			b.Buffer.Write(p[:remaining])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
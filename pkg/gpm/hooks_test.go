@@ -0,0 +1,77 @@
+package gpm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInstallHooks(t *testing.T) {
+	tmp := t.TempDir()
+	storePath := filepath.Join(tmp, "store")
+	binPath := filepath.Join(tmp, "bin")
+	executables := map[string]string{}
+	for _, tag := range []string{"v1.0.0", "v2.0.0"} {
+		dir := filepath.Join(storePath, "github.com", "owner", "tool", tag, "tool_linux_amd64")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		executables[tag] = filepath.Join(dir, "tool")
+		if err := os.WriteFile(executables[tag], []byte("#!/bin/sh\necho tool "+tag+"\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("GITHUB_TOKEN", "secret")
+	gpm := NewGPM(WithStorePath(storePath), WithBinPath(binPath), WithConfigPath(filepath.Join(tmp, "config")), WithOffline(true))
+	ctx := context.Background()
+	linkPath := filepath.Join(binPath, "tool")
+
+	tests := []struct {
+		name    string
+		pkg     Package
+		want    string
+		wantErr bool
+	}{
+		{"No hooks", Package{}, executables["v2.0.0"], false},
+		{"Test passes", Package{Test: &SmokeTest{Command: "{bin} --version", Expect: `^tool v2\.`}}, executables["v2.0.0"], false},
+		{"Test fails", Package{Test: &SmokeTest{Command: "{bin:tool} --version", Expect: `^tool v3\.`}}, executables["v1.0.0"], true},
+		{"Unknown executable", Package{Test: &SmokeTest{Command: "{bin:other}"}}, executables["v1.0.0"], true},
+		{"Hook runs in temporary home", Package{PostInstall: []string{`test "$HOME" = "$TMPDIR" && test "$HOME" != ` + shellQuote(os.Getenv("HOME"))}}, executables["v2.0.0"], false},
+		{"Hook runs without secrets", Package{PostInstall: []string{`test -z "$GITHUB_TOKEN"`}}, executables["v2.0.0"], false},
+		{"Remote registry", Package{PostInstall: []string{"exit 1"}, Source: "https://example.com/registry.yaml"}, executables["v2.0.0"], false},
+		{"Hook fails", Package{Test: &SmokeTest{Command: "tool"}, PostInstall: []string{"exit 1"}}, executables["v1.0.0"], true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := gpm.InstallDependency(ctx, Dependency{Owner: "owner", Repo: "tool", ReleaseTag: "v1.0.0"}, nil); err != nil {
+				t.Fatal(err)
+			}
+			pkg := tt.pkg
+			_, err := gpm.InstallDependency(ctx, Dependency{Owner: "owner", Repo: "tool", ReleaseTag: "v2.0.0", Package: &pkg}, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InstallDependency() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var hookErr *HookError
+			if tt.wantErr && !errors.As(err, &hookErr) {
+				t.Errorf("InstallDependency() error = %v, want a HookError", err)
+			}
+			if target, err := readLink(linkPath); err != nil || target != tt.want {
+				t.Errorf("link targets %q, %v, want %q", target, err, tt.want)
+			}
+		})
+	}
+
+	// A new link is removed when its hooks fail.
+	if err := os.Remove(linkPath); err != nil {
+		t.Fatal(err)
+	}
+	pkg := Package{PostInstall: []string{"false"}}
+	if _, err := gpm.InstallDependency(ctx, Dependency{Owner: "owner", Repo: "tool", ReleaseTag: "v2.0.0", Package: &pkg}, nil); err == nil {
+		t.Fatal("InstallDependency() succeeded with a failing hook")
+	}
+	if _, err := os.Lstat(linkPath); !os.IsNotExist(err) {
+		t.Errorf("InstallDependency() left link %q: %v", linkPath, err)
+	}
+}
@@ -0,0 +1,188 @@
+package gpm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Tool is a tool declared in the configuration of another tool manager.
+type Tool struct {
+	// Name identifies the tool in the other tool manager (eg. ripgrep, cli/cli or ubi:BurntSushi/ripgrep).
+	Name    string
+	Version string
+	// Source is the file declaring the tool.
+	Source string
+}
+
+// UnmappedTool is a [Tool] that could not be mapped to a gpm dependency.
+type UnmappedTool struct {
+	Tool   Tool
+	Reason string
+}
+
+// ParseToolsFile reads the tools declared in an aqua.yaml, .tool-versions or mise.toml file,
+// recognized by its name.
+func ParseToolsFile(filePath string) ([]Tool, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", filePath, err)
+	}
+	switch name := filepath.Base(filePath); {
+	case name == ".tool-versions":
+		return ParseToolVersions(data, filePath)
+	case strings.HasSuffix(name, ".toml"):
+		return ParseMise(data, filePath)
+	case strings.HasSuffix(name, ".yaml"), strings.HasSuffix(name, ".yml"):
+		return ParseAqua(data, filePath)
+	default:
+		return nil, fmt.Errorf("unknown tools file format %q", filePath)
+	}
+}
+
+// ToolsFilesNames are the files names looked up by gpm import in the current directory.
+var ToolsFilesNames = []string{"aqua.yaml", "aqua.yml", ".aqua.yaml", ".aqua.yml", ".tool-versions", "mise.toml", ".mise.toml"}
+
+// ParseAqua reads the packages of an aqua.yaml file.
+func ParseAqua(data []byte, source string) ([]Tool, error) {
+	var config struct {
+		Packages []struct {
+			Name    string `yaml:"name"`
+			Version string `yaml:"version"`
+			Import  string `yaml:"import"`
+		} `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", source, err)
+	}
+	tools := make([]Tool, 0, len(config.Packages))
+	for _, pkg := range config.Packages {
+		if pkg.Import != "" {
+			tools = append(tools, Tool{Name: "import:" + pkg.Import, Source: source})
+			continue
+		}
+		name, version, _ := strings.Cut(pkg.Name, "@")
+		if pkg.Version != "" {
+			version = pkg.Version
+		}
+		tools = append(tools, Tool{Name: name, Version: version, Source: source})
+	}
+	return tools, nil
+}
+
+// ParseToolVersions reads an asdf .tool-versions file. Only the first version of each tool is kept.
+func ParseToolVersions(data []byte, source string) ([]Tool, error) {
+	var tools []Tool
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		tool := Tool{Name: fields[0], Source: source}
+		if len(fields) > 1 {
+			tool.Version = fields[1]
+		}
+		tools = append(tools, tool)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", source, err)
+	}
+	return tools, nil
+}
+
+// ParseMise reads the [tools] table of a mise.toml file. Only the first version of each tool is kept.
+func ParseMise(data []byte, source string) ([]Tool, error) {
+	var config struct {
+		Tools map[string]any `toml:"tools"`
+	}
+	if err := toml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", source, err)
+	}
+	tools := make([]Tool, 0, len(config.Tools))
+	for _, name := range sortedKeys(config.Tools) {
+		tool := Tool{Name: name, Source: source}
+		value := config.Tools[name]
+		if versions, ok := value.([]any); ok && len(versions) > 0 {
+			value = versions[0]
+		}
+		switch value := value.(type) {
+		case string:
+			tool.Version = value
+		case map[string]any:
+			tool.Version, _ = value["version"].(string)
+		}
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+// Backends of mise tools names that do not install Github releases.
+var unsupportedBackends = map[string]bool{
+	"cargo": true, "npm": true, "go": true, "pipx": true, "gem": true, "spm": true, "dotnet": true, "vfox": true, "import": true,
+}
+
+// MapTools converts tools to dependencies strings for a [Manifest]. Tools are matched by name, alias
+// or repository against registry packages, and tools named OWNER/REPOSITORY are kept as is.
+func (gpm GPM) MapTools(ctx context.Context, tools []Tool) ([]string, []UnmappedTool, error) {
+	registries, err := gpm.LoadRegistries(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var deps []string
+	var unmapped []UnmappedTool
+	seen := map[string]bool{}
+	for _, tool := range tools {
+		name := tool.Name
+		if backend, rest, ok := strings.Cut(name, ":"); ok {
+			if unsupportedBackends[backend] {
+				unmapped = append(unmapped, UnmappedTool{tool, fmt.Sprintf("%q tools are not Github releases", backend)})
+				continue
+			}
+			name = rest
+		}
+		// Drop mise backend options like ubi:BurntSushi/ripgrep[exe=rg].
+		name, _, _ = strings.Cut(name, "[")
+
+		var spec string
+		if strings.Contains(name, "/") {
+			if strings.Count(name, "/") != 1 {
+				unmapped = append(unmapped, UnmappedTool{tool, "name is not a Github OWNER/REPOSITORY"})
+				continue
+			}
+			spec = name
+			if pkg := lookupPackageByRepo(registries, name); pkg != nil {
+				spec = pkg.Name
+			}
+		} else if pkg := lookupPackage(registries, name); pkg != nil {
+			spec = pkg.Name
+		} else {
+			unmapped = append(unmapped, UnmappedTool{tool, "unknown tool, add it to a registry or use OWNER/REPOSITORY"})
+			continue
+		}
+
+		switch version := tool.Version; {
+		case version == "" || version == "latest":
+		case version == "system" || strings.HasPrefix(version, "ref:") || strings.HasPrefix(version, "path:") || strings.HasPrefix(version, "prefix:"):
+			unmapped = append(unmapped, UnmappedTool{tool, fmt.Sprintf("version %q is not a release tag", version)})
+			continue
+		default:
+			spec += "@" + version
+		}
+		if !seen[spec] {
+			seen[spec] = true
+			deps = append(deps, spec)
+		}
+	}
+	sort.Strings(deps)
+	return deps, unmapped, nil
+}
@@ -0,0 +1,42 @@
+package gpm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestMapTools(t *testing.T) {
+	var tools []Tool
+	for _, parse := range []struct {
+		parse func([]byte, string) ([]Tool, error)
+		data  string
+	}{
+		{ParseAqua, "packages:\n  - name: cli/cli@v2.40.0\n  - name: junegunn/fzf\n    version: 0.44.0\n  - name: golang.org/x/tools/gopls@v0.14.0\n"},
+		{ParseToolVersions, "# tools\nripgrep 14.1.0 13.0.0\nnodejs 20.1.0\njq system\n"},
+		{ParseMise, "[tools]\n\"ubi:sharkdp/fd[exe=fd]\" = \"latest\"\n\"npm:prettier\" = \"3\"\nyq = [\"4.40.5\", \"4.35.1\"]\n"},
+	} {
+		parsed, err := parse.parse([]byte(parse.data), "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tools = append(tools, parsed...)
+	}
+
+	deps, unmapped, err := NewGPM(WithConfigPath(t.TempDir())).MapTools(context.Background(), tools)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDeps := []string{"fd", "gh@v2.40.0", "junegunn/fzf@0.44.0", "rg@14.1.0", "yq@4.40.5"}
+	if !reflect.DeepEqual(deps, wantDeps) {
+		t.Errorf("MapTools() deps = %v, want %v", deps, wantDeps)
+	}
+	var unmappedNames []string
+	for _, u := range unmapped {
+		unmappedNames = append(unmappedNames, u.Tool.Name)
+	}
+	wantUnmapped := []string{"golang.org/x/tools/gopls", "nodejs", "jq", "npm:prettier"}
+	if !reflect.DeepEqual(unmappedNames, wantUnmapped) {
+		t.Errorf("MapTools() unmapped = %v, want %v", unmappedNames, wantUnmapped)
+	}
+}
@@ -0,0 +1,139 @@
+package gpm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// LinkInfo describes an executable linked from a bin dir to a store entry.
+type LinkInfo struct {
+	Link   string `json:"link"`
+	Target string `json:"target"`
+	Host   string `json:"host"`
+	Owner  string `json:"owner"`
+	Repo   string `json:"repo"`
+	Tag    string `json:"tag"`
+	Asset  string `json:"asset"`
+	// File is the path of the executable inside the asset.
+	File string `json:"file"`
+	// Metadata is nil for entries installed by older versions of gpm.
+	Metadata *EntryMetadata `json:"metadata,omitempty"`
+}
+
+// Which resolves the executable name, from the bin dir or else from the PATH, to the store entry it links to.
+func (gpm GPM) Which(name string) (*LinkInfo, error) {
+	binPath, err := gpm.GetBinPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bin path: %w", err)
+	}
+	symLinkPath := filepath.Join(binPath, name)
+	if _, err := os.Lstat(symLinkPath); errors.Is(err, os.ErrNotExist) {
+		if symLinkPath, err = exec.LookPath(name); err != nil {
+			return nil, fmt.Errorf("%q is neither in bin dir %q nor in PATH", name, binPath)
+		}
+	}
+	target, err := readLink(symLinkPath)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a link installed by gpm: %w", symLinkPath, err)
+	}
+	dep, entryPath, ok := gpm.StoreDependency(target)
+	if !ok {
+		return nil, fmt.Errorf("%q links to %q, outside of the gpm store", symLinkPath, target)
+	}
+	file, err := filepath.Rel(entryPath, target)
+	if err != nil {
+		return nil, err
+	}
+	info := &LinkInfo{
+		Link:   symLinkPath,
+		Target: target,
+		Host:   "github.com",
+		Owner:  dep.Owner,
+		Repo:   dep.Repo,
+		Tag:    dep.ReleaseTag,
+		Asset:  dep.AssetName,
+		File:   filepath.ToSlash(file),
+	}
+	if metadata, err := ReadEntryMetadata(entryPath); err == nil {
+		info.Metadata = metadata
+	}
+	return info, nil
+}
+
+// InstalledAsset is a store entry of a repository, with the links of known bin dirs to its executables.
+type InstalledAsset struct {
+	Tag   string   `json:"tag"`
+	Asset string   `json:"asset"`
+	Path  string   `json:"path"`
+	Size  int64    `json:"size"`
+	Links []string `json:"links,omitempty"`
+	// Metadata is nil for entries installed by older versions of gpm.
+	Metadata *EntryMetadata `json:"metadata,omitempty"`
+}
+
+// InstalledAssets returns the store entries of owner/repo, sorted by tag.
+func (gpm GPM) InstalledAssets(ctx context.Context, owner, repo string) ([]InstalledAsset, error) {
+	entries, err := gpm.ListStoreEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	links, err := gpm.storeLinks()
+	if err != nil {
+		return nil, err
+	}
+	var assets []InstalledAsset
+	for _, entry := range entries {
+		if !strings.EqualFold(entry.Owner, owner) || !strings.EqualFold(entry.Repo, repo) {
+			continue
+		}
+		asset := InstalledAsset{
+			Tag:   entry.ReleaseTag,
+			Asset: entry.AssetName,
+			Path:  entry.Path,
+			Size:  entry.Size,
+			Links: links[entry.Path],
+		}
+		if metadata, err := ReadEntryMetadata(entry.Path); err == nil {
+			asset.Metadata = metadata
+		}
+		assets = append(assets, asset)
+	}
+	return assets, nil
+}
+
+// storeLinks returns the links of the bin dir and known bin dirs, by the store entry they point into.
+func (gpm GPM) storeLinks() (map[string][]string, error) {
+	binPaths, err := gpm.KnownBinPaths()
+	if err != nil {
+		return nil, err
+	}
+	if binPath, err := gpm.GetBinPath(); err == nil {
+		binPaths = append([]string{binPath}, binPaths...)
+	}
+	links := map[string][]string{}
+	seen := map[string]bool{}
+	for _, binPath := range binPaths {
+		if seen[binPath] {
+			continue
+		}
+		seen[binPath] = true
+		dirEntries, err := os.ReadDir(binPath)
+		if err != nil {
+			continue
+		}
+		for _, dirEntry := range dirEntries {
+			symLinkPath := filepath.Join(binPath, dirEntry.Name())
+			if target, err := readLink(symLinkPath); err == nil {
+				if _, entryPath, ok := gpm.StoreDependency(target); ok {
+					links[entryPath] = append(links[entryPath], symLinkPath)
+				}
+			}
+		}
+	}
+	return links, nil
+}
@@ -0,0 +1,70 @@
+package gpm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWhich(t *testing.T) {
+	tmp := t.TempDir()
+	storePath := filepath.Join(tmp, "store")
+	binPath := filepath.Join(tmp, "bin")
+	entry := filepath.Join(storePath, "github.com", "owner", "tool", "v1.0.0", "tool_linux_amd64.tar.gz")
+	for _, dir := range []string{filepath.Join(entry, "tool_linux_amd64"), binPath} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	executable := filepath.Join(entry, "tool_linux_amd64", "tool")
+	if err := os.WriteFile(executable, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, target := range map[string]string{"tool": executable, "other": filepath.Join(tmp, "other")} {
+		if err := os.Symlink(target, filepath.Join(binPath, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gpm := NewGPM(WithStorePath(storePath), WithBinPath(binPath))
+	t.Setenv("PATH", "")
+
+	tests := []struct {
+		name    string
+		want    *LinkInfo
+		wantErr bool
+	}{
+		{"tool", &LinkInfo{
+			Link:   filepath.Join(binPath, "tool"),
+			Target: executable,
+			Host:   "github.com",
+			Owner:  "owner",
+			Repo:   "tool",
+			Tag:    "v1.0.0",
+			Asset:  "tool_linux_amd64.tar.gz",
+			File:   "tool_linux_amd64/tool",
+		}, false},
+		{"other", nil, true},
+		{"missing", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := gpm.Which(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Which() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Which() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	assets, err := gpm.InstalledAssets(context.Background(), "owner", "tool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assets) != 1 || !reflect.DeepEqual(assets[0].Links, []string{filepath.Join(binPath, "tool")}) {
+		t.Errorf("InstalledAssets() = %+v, want one asset linked from tool", assets)
+	}
+}
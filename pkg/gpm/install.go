@@ -2,87 +2,431 @@ package gpm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/go-github/v47/github"
 	"github.com/hashicorp/go-getter/v2"
 )
 
-func (gpm GPM) InstallDependency(ctx context.Context, dep Dependency, progressTracker getter.ProgressTracker) error {
-	gh := github.NewClient(nil)
-	release, _, err := gh.Repositories.GetReleaseByTag(ctx, dep.Owner, dep.Repo, dep.ReleaseTag)
+// InstallDependency downloads, extracts and links dep, and returns it resolved. See [GPM.FetchDependency].
+// The smoke test and post-install hooks of the package of dep are then run (see [Package.Test]), and the
+// links are restored as they were if one of them fails, with a [HookError].
+func (gpm GPM) InstallDependency(ctx context.Context, dep Dependency, progressTracker getter.ProgressTracker) (Dependency, error) {
+	dep, executables, unlock, err := gpm.fetchDependency(ctx, dep, progressTracker)
 	if err != nil {
-		return fmt.Errorf("failed to get release %s/%s@%s: %w", dep.Owner, dep.Repo, dep.ReleaseTag, err)
+		return dep, err
 	}
-	for _, asset := range release.Assets {
-		if asset.GetName() == dep.AssetName {
-			get := getter.Client{
-				Getters: []getter.Getter{
-					&getter.HttpGetter{
-						DoNotCheckHeadFirst:   false,
-						XTerraformGetDisabled: true,
-					},
-				},
-				DisableSymlinks: true,
+	replaced, err := gpm.linkExecutables(ctx, executables)
+	unlock()
+	if err != nil {
+		return dep, err
+	}
+	if err := gpm.runHooks(ctx, dep, executables); err != nil {
+		// Links are restored even if ctx was canceled while running hooks.
+		if restoreErr := gpm.restoreLinks(context.Background(), replaced); restoreErr != nil {
+			log.Printf("Failed to restore links of %q: %s", dep, restoreErr.Error())
+		}
+		return dep, err
+	}
+	return dep, nil
+}
+
+// FetchDependency downloads and extracts dep into the store without linking it, and returns it resolved
+// with the paths of its executables, by link name. A dependency whose owner and release tag are known is
+// taken from the store without network access if it was already downloaded. In offline mode, or when
+// the network is unreachable, dependencies are only taken from the store. When ctx is canceled, the
+// staged download is removed. Assets of moving tags are looked up online unless a stamped tag is given,
+// and stored under a stamped tag (see [IsMovingTag]).
+func (gpm GPM) FetchDependency(ctx context.Context, dep Dependency, progressTracker getter.ProgressTracker) (Dependency, map[string]string, error) {
+	dep, executables, unlock, err := gpm.fetchDependency(ctx, dep, progressTracker)
+	if err != nil {
+		return dep, nil, err
+	}
+	unlock()
+	return dep, executables, nil
+}
+
+// fetchDependency is [GPM.FetchDependency] returning with the entry lock of dep held, so that [GPM.GC]
+// does not remove the entry before it is linked or run. unlock must be called to release it.
+func (gpm GPM) fetchDependency(ctx context.Context, dep Dependency, progressTracker getter.ProgressTracker) (_ Dependency, _ map[string]string, unlock func(), err error) {
+	if gpm.offline {
+		return gpm.fetchCached(ctx, dep)
+	}
+	_, lockedStamp := SplitStampedTag(dep.ReleaseTag)
+	if dep.Owner != "" && !IsChannel(dep.ReleaseTag) && !IsConstraint(dep.ReleaseTag) && (lockedStamp != "" || !IsMovingTag(dep)) {
+		if cached, executables, unlock, err := gpm.fetchCached(ctx, dep); err == nil {
+			return cached, executables, unlock, nil
+		} else if !errors.Is(err, ErrNotCached) {
+			log.Printf("Failed to install %q from the store, downloading it again: %s", dep, err.Error())
+		}
+	}
+
+	done, err := gpm.acquire(ctx, gpm.schedulerHost(gpm.GithubClient().BaseURL.String()), 0)
+	if err != nil {
+		return dep, nil, nil, err
+	}
+	notifyState(ctx, StateResolving)
+	release, asset, err := gpm.ResolveAsset(ctx, &dep)
+	done()
+	if err != nil && IsNetworkError(err) && ctx.Err() == nil {
+		return gpm.fallbackCached(ctx, dep, err)
+	}
+	if err != nil {
+		return dep, nil, nil, err
+	}
+	moving := IsMovingTag(dep)
+	if moving {
+		if stampedTag, ok := gpm.findStampedEntry(dep, asset); ok {
+			if err := checkStamp(dep, stampedTag, lockedStamp); err != nil {
+				return dep, nil, nil, err
 			}
-			storePath, err := gpm.GetStorePath()
-			if err != nil {
-				return fmt.Errorf("failed to get gpm store path")
+			log.Printf("Asset %q of moving tag %q is unchanged since it was stored as %q", dep.AssetName, dep.ReleaseTag, stampedTag)
+			dep.ReleaseTag = stampedTag
+			return gpm.fetchCached(ctx, dep)
+		}
+	}
+
+	downloadURL := asset.GetBrowserDownloadURL()
+	done, err = gpm.acquire(ctx, gpm.schedulerHost(downloadURL), int64(asset.GetSize()))
+	if err != nil {
+		return dep, nil, nil, err
+	}
+	defer done()
+	notifyState(ctx, StateDownloading)
+
+	stagingPath, err := gpm.GetStagingPath()
+	if err != nil {
+		return dep, nil, nil, fmt.Errorf("failed to get gpm staging path: %w", err)
+	}
+	staged := filepath.Join(stagingPath, "github.com", dep.Owner, dep.Repo, dep.ReleaseTag, dep.AssetName)
+	if unlock, err = gpm.lockAsset(ctx, dep); err != nil {
+		return dep, nil, nil, err
+	}
+	defer func() {
+		if err != nil {
+			unlock()
+		}
+	}()
+	defer func() {
+		if err == nil || ctx.Err() == nil {
+			return
+		}
+		log.Printf("Install of %q canceled, cleaning up", dep)
+		if err := RemoveDownload(staged); err != nil {
+			log.Printf("Failed to remove %q: %s", staged, err.Error())
+		}
+		removeEmptyParents(filepath.Dir(staged), stagingPath)
+	}()
+	err = gpm.retry(ctx, func(ctx context.Context) error {
+		return gpm.Download(ctx, downloadURL, staged, progressTracker)
+	})
+	if err != nil {
+		return dep, nil, nil, fmt.Errorf("failed to download %q: %w", dep, err)
+	}
+	notifyState(ctx, StateInstalling)
+	metadata := EntryMetadata{
+		URL:            downloadURL,
+		Source:         downloadSource(staged),
+		AssetID:        asset.GetID(),
+		AssetUpdatedAt: asset.GetUpdatedAt().Time,
+	}
+	if dep.Package != nil {
+		if checksumAssetName, ok := dep.Package.ChecksumAsset(dep.AssetName, AssetsNames(release)); ok {
+			for _, checksumAsset := range release.Assets {
+				if checksumAsset.GetName() != checksumAssetName {
+					continue
+				}
+				checksums, err := gpm.Fetch(ctx, checksumAsset.GetBrowserDownloadURL())
+				if err != nil {
+					return dep, nil, nil, fmt.Errorf("failed to get checksums of %q: %w", dep, err)
+				}
+				if err := VerifyChecksum(staged, dep.AssetName, checksums); err != nil {
+					if err := RemoveDownload(staged); err != nil {
+						log.Printf("Failed to remove %q: %s", staged, err.Error())
+					}
+					return dep, nil, nil, err
+				}
+				log.Printf("Checksum of %q verified with %q", dep.AssetName, checksumAssetName)
+				metadata.VerifiedWith = checksumAssetName
 			}
-			dst := filepath.Join(storePath, "github.com", dep.Owner, dep.Repo, dep.ReleaseTag, dep.AssetName)
-			_, err = get.Get(ctx, &getter.Request{
-				Src:              asset.GetBrowserDownloadURL(),
-				Dst:              dst,
-				Forced:           "http",
-				GetMode:          getter.ModeAny,
-				DisableSymlinks:  true,
-				ProgressListener: progressTracker,
-			})
-			if err != nil {
-				return fmt.Errorf("failed to download %q: %w", dep, err)
+		}
+	}
+
+	if moving {
+		digest, _, err := fileSHA256(staged)
+		if err != nil {
+			return dep, nil, nil, fmt.Errorf("failed to hash %q: %w", staged, err)
+		}
+		stampedTag := StampTag(dep.ReleaseTag, digest)
+		if err := checkStamp(dep, stampedTag, lockedStamp); err != nil {
+			if err := RemoveDownload(staged); err != nil {
+				log.Printf("Failed to remove %q: %s", staged, err.Error())
 			}
-			log.Printf("Asset downloaded to %q", dst)
-			dirEntries, err := os.ReadDir(dst)
-			if err != nil {
-				return fmt.Errorf("failed to open %q as directory: %w", dst, err)
+			return dep, nil, nil, err
+		}
+		dep.ReleaseTag = stampedTag
+	}
+
+	dep, executables, err := gpm.storeAsset(ctx, dep, staged, metadata)
+	if err != nil {
+		return dep, nil, nil, err
+	}
+	if err := RemoveDownload(staged); err != nil {
+		log.Printf("Failed to remove %q: %s", staged, err.Error())
+	}
+	return dep, executables, unlock, nil
+}
+
+// storeAsset extracts the asset downloaded at src into the store entry of dep and saves the entry metadata,
+// completed with the digest of src. It returns dep with the executables of the entry to link, by link name.
+// An entry already holding the same asset is kept as is. Entries are never replaced, since they may be in
+// use: when the asset of a release tag changed since it was stored, it is stored under the tag stamped
+// with its digest, like assets of moving tags (see [StampTag]), and dep is returned with this tag.
+// The entry lock of dep must be held.
+func (gpm GPM) storeAsset(ctx context.Context, dep Dependency, src string, metadata EntryMetadata) (Dependency, map[string]string, error) {
+	storePath, err := gpm.GetStorePath()
+	if err != nil {
+		return dep, nil, fmt.Errorf("failed to get gpm store path: %w", err)
+	}
+	if metadata.SHA256, metadata.Size, err = fileSHA256(src); err != nil {
+		return dep, nil, fmt.Errorf("failed to hash %q: %w", src, err)
+	}
+	dst := filepath.Join(storePath, "github.com", dep.Owner, dep.Repo, dep.ReleaseTag, dep.AssetName)
+	if digest, ok := storedDigest(dst); ok && digest != metadata.SHA256 {
+		tag, stamp := SplitStampedTag(dep.ReleaseTag)
+		if stamp != "" {
+			return dep, nil, fmt.Errorf("store entry %q holds another asset than %s, run gpm doctor", dst, dep)
+		}
+		log.Printf("Asset %q changed since it was stored, storing it under a stamped tag", dep)
+		dep.ReleaseTag = StampTag(tag, metadata.SHA256)
+		dst = filepath.Join(storePath, "github.com", dep.Owner, dep.Repo, dep.ReleaseTag, dep.AssetName)
+	}
+	if digest, ok := storedDigest(dst); ok && digest == metadata.SHA256 {
+		log.Printf("Asset %q is already in the store at %q", dep, dst)
+		executables, err := FindExecutables(dst, dep)
+		return dep, executables, err
+	}
+
+	// Extract next to the download, which is on the same filesystem as the store,
+	// and only move the result into the store once it holds the expected executables.
+	extracted, err := os.MkdirTemp(filepath.Dir(src), dep.AssetName+".extract-")
+	if err != nil {
+		return dep, nil, fmt.Errorf("failed to create extraction directory: %w", err)
+	}
+	defer os.RemoveAll(extracted)
+	if err := Extract(src, extracted, dep.AssetName); err != nil {
+		return dep, nil, fmt.Errorf("failed to extract %q: %w", dep, err)
+	}
+	executables, err := FindExecutables(extracted, dep)
+	if err != nil {
+		return dep, nil, err
+	}
+	// Extraction can't be interrupted, check whether the install was canceled meanwhile.
+	if err := ctx.Err(); err != nil {
+		return dep, nil, err
+	}
+	metadata.InstalledAt = time.Now()
+	if err := moveEntry(extracted, dst, metadata); err != nil {
+		return dep, nil, err
+	}
+	for name, filePath := range executables {
+		executables[name] = filepath.Join(dst, strings.TrimPrefix(filePath, extracted))
+	}
+	log.Printf("Asset installed to %q from %q", dst, metadata.Source)
+	return dep, executables, nil
+}
+
+// storedDigest returns the SHA-256 digest of the asset extracted in the store entry at entryPath, if the
+// entry exists. The digest is empty when the entry has no metadata.
+func storedDigest(entryPath string) (string, bool) {
+	if _, err := os.Lstat(entryPath); err != nil {
+		return "", false
+	}
+	metadata, err := ReadEntryMetadata(entryPath)
+	if err != nil {
+		return "", true
+	}
+	return metadata.SHA256, true
+}
+
+// ResolveAsset finds the release and asset to install for dep, and completes dep with the owner,
+// release tag and asset name when they are missing. The release of a moving tag is never served from
+// the API cache without asking Github whether it changed.
+func (gpm GPM) ResolveAsset(ctx context.Context, dep *Dependency) (*github.RepositoryRelease, *github.ReleaseAsset, error) {
+	if dep.Owner == "" {
+		owner, err := gpm.ResolveOwner(ctx, dep.Repo)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve owner of %q: %w", dep, err)
+		}
+		dep.Owner = owner
+	}
+	releaseCtx := ctx
+	if IsMovingTag(*dep) {
+		releaseCtx = withRevalidation(ctx)
+	}
+	release, err := gpm.GetRelease(releaseCtx, dep.Owner, dep.Repo, dep.ReleaseTag)
+	if err != nil {
+		return nil, nil, err
+	}
+	dep.ReleaseTag = release.GetTagName()
+	asset, err := SelectAsset(release, *dep, CurrentPlatform())
+	if err != nil {
+		return nil, nil, err
+	}
+	dep.AssetName = asset.GetName()
+	return release, asset, nil
+}
+
+// SelectAsset returns the asset of release to install for dep on platform. The asset is either the one
+// named by [Dependency.AssetName], the one matching the pattern of [Dependency.Package] for platform, or
+// the first one found by [PlatformAssets].
+func SelectAsset(release *github.RepositoryRelease, dep Dependency, platform Platform) (*github.ReleaseAsset, error) {
+	assetName := dep.AssetName
+	if assetName == "" {
+		var ok bool
+		if dep.Package != nil {
+			if pattern, found := dep.Package.AssetPattern(platform); found {
+				if assetName, ok = MatchAsset(pattern, AssetsNames(release)); !ok {
+					return nil, fmt.Errorf("no asset matching %q in release %s/%s@%s", pattern, dep.Owner, dep.Repo, release.GetTagName())
+				}
 			}
-			binPath, err := gpm.GetBinPath()
+		}
+		if !ok {
+			candidates := PlatformAssets(AssetsNames(release), platform)
+			if len(candidates) == 0 {
+				return nil, fmt.Errorf("no asset for %s in release %s/%s@%s", platform, dep.Owner, dep.Repo, release.GetTagName())
+			}
+			assetName = candidates[0]
+		}
+	}
+	for _, asset := range release.Assets {
+		if asset.GetName() == assetName {
+			return asset, nil
+		}
+	}
+	return nil, fmt.Errorf("asset named %q not found in release %s/%s@%s", assetName, dep.Owner, dep.Repo, release.GetTagName())
+}
+
+// regexpExecutable matches file names that look like executables (no extension, except a version).
+var regexpExecutable = regexp.MustCompile(`^[^.]*((\d+\.){2}\d+)?[^.]*$`)
+
+// FindExecutables returns the executables to link from a downloaded asset directory, by link name.
+// Executables are those declared by [Package.Bin] or else the first file in dir that looks like
+// an executable, named after the repository. Executables are made executable by the owner.
+func FindExecutables(dir string, dep Dependency) (map[string]string, error) {
+	executables := map[string]string{}
+	if dep.Package != nil && len(dep.Package.Bin) > 0 {
+		for name, pattern := range dep.Package.Bin {
+			matches, err := filepath.Glob(filepath.Join(dir, pattern))
 			if err != nil {
-				return fmt.Errorf("failed to get bin path: %w", err)
+				return nil, fmt.Errorf("invalid bin pattern %q: %w", pattern, err)
 			}
-			r := regexp.MustCompile(`^[^.]*((\d+\.){2}\d+)?[^.]*$`)
-			for _, dirEntry := range dirEntries {
-				filePath := filepath.Join(dst, dirEntry.Name())
-				fileInfo, err := dirEntry.Info()
-				if err != nil {
-					log.Printf("Failed to get file stat from %q: %s", filePath, err.Error())
-					continue
-				}
-				if fileInfo.Mode().IsRegular() && r.MatchString(fileInfo.Name()) {
-					if fileInfo.Mode()&0100 == 0 {
-						if err := os.Chmod(filePath, fileInfo.Mode()|0100); err != nil {
-							return fmt.Errorf("failed to chmod 500 %q: %w", filePath, err)
-						}
-					}
-					symLinkPath := filepath.Join(binPath, dep.Repo)
-					if _, err := os.Readlink(symLinkPath); err == nil {
-						if err := os.Remove(symLinkPath); err != nil {
-							return fmt.Errorf("failed to remove symlink %q: %w", symLinkPath, err)
-						}
-					}
-					if err := os.Symlink(filePath, symLinkPath); err != nil {
-						return fmt.Errorf("failed to symlink %q -> %q: %w", symLinkPath, filePath, err)
-					}
-					log.Printf("Symlinked %q -> %q", symLinkPath, filePath)
-					return nil
+			for _, match := range matches {
+				if fileInfo, err := os.Stat(match); err == nil && fileInfo.Mode().IsRegular() {
+					executables[name] = match
+					break
 				}
 			}
-			return nil
+			if _, ok := executables[name]; !ok {
+				return nil, fmt.Errorf("no file matching %q in %q", pattern, dir)
+			}
 		}
+	} else {
+		dirEntries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %q as directory: %w", dir, err)
+		}
+		for _, dirEntry := range dirEntries {
+			filePath := filepath.Join(dir, dirEntry.Name())
+			fileInfo, err := dirEntry.Info()
+			if err != nil {
+				log.Printf("Failed to get file stat from %q: %s", filePath, err.Error())
+				continue
+			}
+			if fileInfo.Mode().IsRegular() && regexpExecutable.MatchString(fileInfo.Name()) {
+				executables[dep.Repo] = filePath
+				break
+			}
+		}
+	}
+	for _, filePath := range executables {
+		fileInfo, err := os.Stat(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %q: %w", filePath, err)
+		}
+		if fileInfo.Mode()&0100 == 0 {
+			if err := os.Chmod(filePath, fileInfo.Mode()|0100); err != nil {
+				return nil, fmt.Errorf("failed to chmod 500 %q: %w", filePath, err)
+			}
+		}
+	}
+	return executables, nil
+}
+
+// moveEntry writes the metadata of the store entry dst, then moves the directory src, on the same
+// filesystem, to dst, which must not exist. The rename is atomic, so an entry is either complete or
+// missing, even if gpm is interrupted.
+func moveEntry(src, dst string, metadata EntryMetadata) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %q: %w", filepath.Dir(dst), err)
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("failed to move %q to %q: %w", src, dst, os.ErrExist)
+	}
+	if err := writeEntryMetadata(dst, metadata); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		if err := os.Remove(EntryMetadataPath(dst)); err != nil {
+			log.Printf("Failed to remove %q: %s", EntryMetadataPath(dst), err.Error())
+		}
+		return fmt.Errorf("failed to move %q to %q: %w", src, dst, err)
+	}
+	return nil
+}
+
+// removeEmptyParents removes dir and its parents while they are empty, up to root excluded.
+func removeEmptyParents(dir, root string) {
+	for dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// link creates a symlink at symLinkPath pointing to filePath, replacing an existing symlink or shim.
+// The symlink is created under a temporary name then renamed, so that symLinkPath never goes missing.
+func link(symLinkPath, filePath string) error {
+	if err := checkReplaceable(symLinkPath); err != nil {
+		return fmt.Errorf("failed to symlink %q -> %q: %w", symLinkPath, filePath, err)
+	}
+	tmpPath := filepath.Join(filepath.Dir(symLinkPath), fmt.Sprintf(".%s.tmp-%d", filepath.Base(symLinkPath), rand.Int63()))
+	if err := os.Symlink(filePath, tmpPath); err != nil {
+		return fmt.Errorf("failed to symlink %q -> %q: %w", tmpPath, filePath, err)
+	}
+	if err := os.Rename(tmpPath, symLinkPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace symlink %q: %w", symLinkPath, err)
+	}
+	log.Printf("Symlinked %q -> %q", symLinkPath, filePath)
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
 	}
-	return fmt.Errorf("asset named %q not found in release %s/%s@%s", dep.AssetName, dep.Owner, dep.Repo, dep.ReleaseTag)
+	sort.Strings(keys)
+	return keys
 }
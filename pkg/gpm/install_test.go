@@ -0,0 +1,90 @@
+package gpm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStoreAsset(t *testing.T) {
+	tmp := t.TempDir()
+	storePath := filepath.Join(tmp, "store")
+	gpm := NewGPM(WithStorePath(storePath))
+	ctx := context.Background()
+	dep := Dependency{Owner: "owner", Repo: "tool", ReleaseTag: "v1.0.0", AssetName: "tool"}
+	entryPath := filepath.Join(storePath, "github.com", "owner", "tool", "v1.0.0", "tool")
+
+	tests := []struct {
+		name    string
+		content string
+		wantTag string
+	}{
+		{"Stored", "v1", "v1.0.0"},
+		{"Same asset", "v1", "v1.0.0"},
+		{"Changed asset", "v2", StampTag("v1.0.0", sha256Hex("v2"))},
+		{"Changed asset again", "v2", StampTag("v1.0.0", sha256Hex("v2"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := filepath.Join(tmp, "staging", "tool")
+			if err := os.MkdirAll(filepath.Dir(src), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(src, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			stored, executables, err := gpm.storeAsset(ctx, dep, src, EntryMetadata{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.ReleaseTag != tt.wantTag {
+				t.Errorf("storeAsset() stored tag %q, want %q", stored.ReleaseTag, tt.wantTag)
+			}
+			if data, err := os.ReadFile(executables["tool"]); err != nil || string(data) != tt.content {
+				t.Errorf("storeAsset() executable holds %q, %v, want %q", data, err, tt.content)
+			}
+			// The first entry is never replaced.
+			if data, err := os.ReadFile(filepath.Join(entryPath, "tool")); err != nil || string(data) != "v1" {
+				t.Errorf("storeAsset() changed the first entry to %q, %v", data, err)
+			}
+			if cached, err := gpm.FindCachedDependency(ctx, Dependency{Owner: "owner", Repo: "tool", ReleaseTag: "v1.0.0"}); err != nil || cached.ReleaseTag != tt.wantTag {
+				t.Errorf("FindCachedDependency() = %q, %v, want %q", cached.ReleaseTag, err, tt.wantTag)
+			}
+			if entries, _ := os.ReadDir(filepath.Dir(src)); len(entries) != 1 {
+				t.Errorf("storeAsset() left %d entries in staging, want the download only", len(entries))
+			}
+		})
+	}
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestLink(t *testing.T) {
+	tmp := t.TempDir()
+	symLinkPath := filepath.Join(tmp, "tool")
+	for _, target := range []string{"v1/tool", "v2/tool"} {
+		if err := link(symLinkPath, target); err != nil {
+			t.Fatalf("link(%q) error = %v", target, err)
+		}
+		if got, err := os.Readlink(symLinkPath); err != nil || got != target {
+			t.Errorf("link(%q) points to %q, %v", target, got, err)
+		}
+	}
+	if entries, _ := os.ReadDir(tmp); len(entries) != 1 {
+		t.Errorf("link() left %d entries in bin dir, want 1", len(entries))
+	}
+
+	regular := filepath.Join(tmp, "regular")
+	if err := os.WriteFile(regular, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := link(regular, "v1/tool"); err == nil {
+		t.Error("link() over a regular file succeeded, want error")
+	}
+}
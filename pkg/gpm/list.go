@@ -23,7 +23,8 @@ func (gpm GPM) ListDownloadedDependencies(ctx context.Context) ([]Dependency, er
 	var deps []Dependency
 
 	for _, dirEntry := range dirEntries {
-		if !dirEntry.IsDir() {
+		// Skip files and internal directories like the staging area.
+		if !dirEntry.IsDir() || strings.HasPrefix(dirEntry.Name(), ".") {
 			continue
 		}
 		domainName := dirEntry.Name()
@@ -101,11 +102,13 @@ func (gpm GPM) ListLinkedDependencies(ctx context.Context) ([]LinkedDependencies
 	linkedDependencies := make([]LinkedDependencies, 0, len(dirEntries))
 
 	for _, dirEntry := range dirEntries {
-		if dirEntry.Type()&os.ModeSymlink == os.ModeSymlink {
+		if dirEntry.Type()&os.ModeSymlink == os.ModeSymlink || dirEntry.Type().IsRegular() {
 			filePath := filepath.Join(binPath, dirEntry.Name())
-			target, err := os.Readlink(filePath)
+			target, err := readLink(filePath)
 			if err != nil {
-				log.Printf("failed to read link %q: %s", filePath, err.Error())
+				if dirEntry.Type()&os.ModeSymlink == os.ModeSymlink {
+					log.Printf("failed to read link %q: %s", filePath, err.Error())
+				}
 				continue
 			}
 			if strings.HasPrefix(target, storePath) {
@@ -0,0 +1,176 @@
+package gpm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultLockTimeout is the longest time to wait for a lock held by another process, unless
+// [WithLockTimeout] is set.
+const DefaultLockTimeout = 5 * time.Minute
+
+// ErrLockTimeout is returned when a lock is still held by another process after the lock timeout.
+var ErrLockTimeout = errors.New("timed out waiting for lock")
+
+// WithLockTimeout sets the longest time to wait for a lock held by another process. Zero waits forever.
+func WithLockTimeout(timeout time.Duration) GPMOption {
+	return func(gpm *GPM) {
+		gpm.lockTimeout = timeout
+	}
+}
+
+// LockEvent describes a wait for a lock held by another process.
+type LockEvent struct {
+	Path string
+	// PID is the process holding the lock, or 0 if unknown.
+	PID int
+}
+
+func (event LockEvent) String() string {
+	if event.PID == 0 {
+		return "waiting for lock held by another process"
+	}
+	return fmt.Sprintf("waiting for lock held by PID %d", event.PID)
+}
+
+type lockNotifierKey struct{}
+
+// WithLockNotifier returns a context whose operations report waits for locks to notify.
+func WithLockNotifier(ctx context.Context, notify func(LockEvent)) context.Context {
+	return context.WithValue(ctx, lockNotifierKey{}, notify)
+}
+
+// lockAsset locks the store entry of an asset, to download and extract it, or to link or run it before
+// [GPM.GC] can remove it. Entries stored under stamped tags share the lock of their release tag, since
+// the stamp is only known once the asset is downloaded (see [StampTag]).
+func (gpm GPM) lockAsset(ctx context.Context, dep Dependency) (func(), error) {
+	tag, _ := SplitStampedTag(dep.ReleaseTag)
+	return gpm.lock(ctx, filepath.Join("github.com", dep.Owner, dep.Repo, tag, dep.AssetName))
+}
+
+// lockLinks locks changes of symlinks in bin dirs.
+func (gpm GPM) lockLinks(ctx context.Context) (func(), error) {
+	return gpm.lock(ctx, "links")
+}
+
+// tryLockAsset locks the store entry of an asset like [GPM.lockAsset], unless another process or install
+// holds it, in which case ok is false.
+func (gpm GPM) tryLockAsset(dep Dependency) (unlock func(), ok bool, err error) {
+	tag, _ := SplitStampedTag(dep.ReleaseTag)
+	return gpm.tryLock(filepath.Join("github.com", dep.Owner, dep.Repo, tag, dep.AssetName))
+}
+
+// lock takes an exclusive advisory lock on the file named name in the locks directory of the store,
+// waiting for other processes to release it. The returned function must be called to release it.
+func (gpm GPM) lock(ctx context.Context, name string) (func(), error) {
+	f, lockPath, err := gpm.openLock(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var deadline <-chan time.Time
+	if gpm.lockTimeout > 0 {
+		timer := time.NewTimer(gpm.lockTimeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for waiting := false; ; waiting = true {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, syscall.EWOULDBLOCK) {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %q: %w", lockPath, err)
+		}
+		event := LockEvent{Path: lockPath, PID: lockHolder(lockPath)}
+		if !waiting {
+			log.Printf("Lock %q is held by PID %d, waiting", lockPath, event.PID)
+			if notify, ok := ctx.Value(lockNotifierKey{}).(func(LockEvent)); ok {
+				notify(event)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, ctx.Err()
+		case <-deadline:
+			f.Close()
+			return nil, fmt.Errorf("%w %q held by PID %d", ErrLockTimeout, lockPath, event.PID)
+		case <-ticker.C:
+		}
+	}
+	return locked(f, lockPath), nil
+}
+
+// tryLock takes the lock of [GPM.lock] without waiting. ok is false if it is held.
+func (gpm GPM) tryLock(name string) (unlock func(), ok bool, err error) {
+	f, lockPath, err := gpm.openLock(name)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to lock %q: %w", lockPath, err)
+	}
+	return locked(f, lockPath), true, nil
+}
+
+// openLock opens the file of the lock named name, creating it if needed.
+func (gpm GPM) openLock(name string) (*os.File, string, error) {
+	storePath, err := gpm.GetStorePath()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get gpm store path: %w", err)
+	}
+	lockPath := filepath.Join(storePath, ".locks", name+".lock")
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		return nil, "", fmt.Errorf("failed to create directory %q: %w", filepath.Dir(lockPath), err)
+	}
+	f, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open lock %q: %w", lockPath, err)
+	}
+	return f, lockPath, nil
+}
+
+// locked writes the PID of gpm to the lock file f, just locked, and returns the function releasing it.
+func locked(f *os.File, lockPath string) func() {
+	if err := f.Truncate(0); err == nil {
+		if _, err := f.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0); err != nil {
+			log.Printf("Failed to write PID to %q: %s", lockPath, err.Error())
+		}
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if err := syscall.Flock(int(f.Fd()), syscall.LOCK_UN); err != nil {
+				log.Printf("Failed to unlock %q: %s", lockPath, err.Error())
+			}
+			f.Close()
+		})
+	}
+}
+
+// lockHolder returns the PID written in the lock file at lockPath by its holder, or 0 if unknown.
+func lockHolder(lockPath string) int {
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		return 0
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid
+}
@@ -0,0 +1,34 @@
+package gpm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLock(t *testing.T) {
+	gpm := NewGPM(WithStorePath(t.TempDir()), WithLockTimeout(200*time.Millisecond))
+	ctx := context.Background()
+
+	unlock, err := gpm.lockLinks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var event LockEvent
+	ctx = WithLockNotifier(ctx, func(e LockEvent) { event = e })
+	if _, err := gpm.lockLinks(ctx); !errors.Is(err, ErrLockTimeout) {
+		t.Fatalf("lockLinks() while locked error = %v, want %v", err, ErrLockTimeout)
+	}
+	if event.PID != os.Getpid() {
+		t.Errorf("lockLinks() notified PID %d, want %d", event.PID, os.Getpid())
+	}
+
+	unlock()
+	unlock, err = gpm.lockLinks(ctx)
+	if err != nil {
+		t.Fatalf("lockLinks() after unlock error = %v", err)
+	}
+	unlock()
+}
@@ -0,0 +1,106 @@
+package gpm
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LockFileName is the name of the lock file written next to a manifest.
+const LockFileName = "gpm.lock"
+
+// LockFile records what the dependencies of a manifest resolved to when they were last installed,
+// so that later installs get the same versions, even offline.
+type LockFile struct {
+	// Dependencies are indexed by their string in the manifest.
+	Dependencies map[string]LockedDependency `yaml:"dependencies"`
+}
+
+// LockedDependency is the resolved form of a manifest dependency.
+type LockedDependency struct {
+	Owner string `yaml:"owner"`
+	Repo  string `yaml:"repo"`
+	Tag   string `yaml:"tag"`
+	// Assets are the installed asset names by platform, like linux/amd64.
+	Assets map[string]string `yaml:"assets,omitempty"`
+	// Digests are the hex encoded SHA-256 digests of assets, by asset name.
+	Digests map[string]string `yaml:"digests,omitempty"`
+}
+
+// LockFilePath returns the path of the lock file of the manifest at manifestPath.
+func LockFilePath(manifestPath string) string {
+	return filepath.Join(filepath.Dir(manifestPath), LockFileName)
+}
+
+// LoadLockFile reads the lock file at lockFilePath. A missing lock file is empty.
+func LoadLockFile(lockFilePath string) (*LockFile, error) {
+	lockFile := LockFile{Dependencies: map[string]LockedDependency{}}
+	data, err := os.ReadFile(lockFilePath)
+	if os.IsNotExist(err) {
+		return &lockFile, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &lockFile); err != nil {
+		return nil, fmt.Errorf("failed to parse lock file %q: %w", lockFilePath, err)
+	}
+	if lockFile.Dependencies == nil {
+		lockFile.Dependencies = map[string]LockedDependency{}
+	}
+	return &lockFile, nil
+}
+
+// Save writes the lock file to lockFilePath.
+func (lockFile LockFile) Save(lockFilePath string) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(lockFile); err != nil {
+		return err
+	}
+	if err := os.WriteFile(lockFilePath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write lock file %q: %w", lockFilePath, err)
+	}
+	return nil
+}
+
+// Apply completes dep, parsed from the manifest string spec, with its locked owner, tag and asset for
+// platform. Dependencies that are not locked are returned unchanged.
+func (lockFile LockFile) Apply(spec string, dep Dependency, platform Platform) Dependency {
+	locked, ok := lockFile.Dependencies[spec]
+	if !ok {
+		return dep
+	}
+	dep.Owner, dep.Repo, dep.ReleaseTag = locked.Owner, locked.Repo, locked.Tag
+	if assetName, ok := locked.Assets[platform.String()]; ok && dep.AssetName == "" {
+		dep.AssetName = assetName
+	}
+	return dep
+}
+
+// ApplyOwner completes dep, parsed from the manifest string spec, with its locked owner when spec has
+// none, so that resolving spec again installs from the same repository.
+func (lockFile LockFile) ApplyOwner(spec string, dep Dependency) Dependency {
+	if locked, ok := lockFile.Dependencies[spec]; ok && dep.Owner == "" && strings.EqualFold(locked.Repo, dep.Repo) {
+		dep.Owner = locked.Owner
+	}
+	return dep
+}
+
+// Record locks the manifest string spec to the installed dependency dep, on platform.
+func (lockFile *LockFile) Record(spec string, dep Dependency, platform Platform) {
+	locked := lockFile.Dependencies[spec]
+	if locked.Owner != dep.Owner || locked.Repo != dep.Repo || locked.Tag != dep.ReleaseTag {
+		locked = LockedDependency{Owner: dep.Owner, Repo: dep.Repo, Tag: dep.ReleaseTag}
+	}
+	if locked.Assets == nil {
+		locked.Assets = map[string]string{}
+	}
+	locked.Assets[platform.String()] = dep.AssetName
+	lockFile.Dependencies[spec] = locked
+}
@@ -0,0 +1,96 @@
+package gpm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLockFile(t *testing.T) {
+	platform := Platform{OS: "linux", Arch: "amd64"}
+	lockFilePath := filepath.Join(t.TempDir(), LockFileName)
+	lockFile, err := LoadLockFile(lockFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lockFile.Record("jq", Dependency{Owner: "jqlang", Repo: "jq", ReleaseTag: "jq-1.7", AssetName: "jq-linux-amd64"}, platform)
+	if err := lockFile.Save(lockFilePath); err != nil {
+		t.Fatal(err)
+	}
+	if lockFile, err = LoadLockFile(lockFilePath); err != nil {
+		t.Fatal(err)
+	}
+	got := lockFile.Apply("jq", Dependency{Repo: "jq"}, platform)
+	want := Dependency{Owner: "jqlang", Repo: "jq", ReleaseTag: "jq-1.7", AssetName: "jq-linux-amd64"}
+	if got != want {
+		t.Errorf("Apply() = %+v, want %+v", got, want)
+	}
+	if got := lockFile.Apply("jq", Dependency{Repo: "jq"}, Platform{OS: "darwin", Arch: "arm64"}); got.AssetName != "" || got.ReleaseTag != "jq-1.7" {
+		t.Errorf("Apply() on another platform = %+v, want tag without asset", got)
+	}
+	if got := lockFile.Apply("fd", Dependency{Repo: "fd"}, platform); got != (Dependency{Repo: "fd"}) {
+		t.Errorf("Apply() of an unlocked dependency = %+v", got)
+	}
+	if got := lockFile.ApplyOwner("jq", Dependency{Repo: "jq", ReleaseTag: "latest"}); got != (Dependency{Owner: "jqlang", Repo: "jq", ReleaseTag: "latest"}) {
+		t.Errorf("ApplyOwner() = %+v, want the locked owner only", got)
+	}
+}
+
+func TestLockFile_ManifestWithAssets(t *testing.T) {
+	tmp := t.TempDir()
+	storePath := filepath.Join(tmp, "store")
+	for _, entry := range []string{"tool/v1.0.0/a", "tool/v1.0.0/b", "other/v2.0.0/other_linux_amd64"} {
+		dir := filepath.Join(storePath, "github.com", "owner", entry)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(dir)), nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gpm := NewGPM(WithStorePath(storePath), WithBinPath(filepath.Join(tmp, "bin")), WithConfigPath(filepath.Join(tmp, "config")), WithOffline(true))
+	ctx := context.Background()
+	platform := CurrentPlatform()
+	manifest := Manifest{Dependencies: []string{"owner/tool:a,b", "owner/other"}}
+	lockFilePath := filepath.Join(tmp, LockFileName)
+	lockFile, err := LoadLockFile(lockFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deps, specs, err := gpm.ConvertManifestDependencies(ctx, manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSpecs := []string{"owner/tool:a,b", "owner/tool:a,b", "owner/other"}
+	if !reflect.DeepEqual(specs, wantSpecs) {
+		t.Fatalf("ConvertManifestDependencies() specs = %q, want %q", specs, wantSpecs)
+	}
+	for i, dep := range deps {
+		installed, err := gpm.InstallDependency(ctx, lockFile.Apply(specs[i], dep, platform), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lockFile.Record(specs[i], installed, platform)
+	}
+	if err := lockFile.Save(lockFilePath); err != nil {
+		t.Fatal(err)
+	}
+	if lockFile, err = LoadLockFile(lockFilePath); err != nil {
+		t.Fatal(err)
+	}
+	for spec, want := range map[string]string{"owner/tool:a,b": "owner/tool@v1.0.0", "owner/other": "owner/other@v2.0.0"} {
+		if locked := lockFile.Dependencies[spec]; locked.Owner+"/"+locked.Repo+"@"+locked.Tag != want {
+			t.Errorf("lock file locks %q to %+v, want %s", spec, locked, want)
+		}
+	}
+
+	// Installing again from the lock file keeps each asset of the manifest.
+	for i, want := range []string{"owner/tool@v1.0.0:a", "owner/tool@v1.0.0:b", "owner/other@v2.0.0:other_linux_amd64"} {
+		if got := lockFile.Apply(specs[i], deps[i], platform); got.String() != want {
+			t.Errorf("Apply(%q) = %s, want %s", specs[i], got, want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package gpm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest lists the dependencies of a project, usually in a gpm.yaml file.
+type Manifest struct {
+	// Dependencies are strings parsed with [GPM.ConvertDependenciesStrings].
+	Dependencies []string `yaml:"dependencies"`
+}
+
+// LoadManifest reads the manifest at manifestPath.
+func LoadManifest(manifestPath string) (*Manifest, error) {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %q: %w", manifestPath, err)
+	}
+	return &manifest, nil
+}
+
+// Save writes the manifest to manifestPath.
+func (manifest Manifest) Save(manifestPath string) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(manifest); err != nil {
+		return err
+	}
+	if err := os.WriteFile(manifestPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest %q: %w", manifestPath, err)
+	}
+	return nil
+}
+
+// ConvertManifestDependencies parses the dependencies of manifest like [GPM.ConvertDependenciesStrings].
+// It also returns the manifest string each dependency was parsed from, by index of the dependencies,
+// since a string listing several assets is parsed into a dependency per asset.
+func (gpm GPM) ConvertManifestDependencies(ctx context.Context, manifest Manifest) ([]Dependency, []string, error) {
+	var deps []Dependency
+	var specs []string
+	for _, spec := range manifest.Dependencies {
+		specDeps, err := gpm.ConvertDependenciesStrings(ctx, spec)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, dep := range specDeps {
+			deps = append(deps, dep)
+			specs = append(specs, spec)
+		}
+	}
+	return deps, specs, nil
+}
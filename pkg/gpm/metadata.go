@@ -0,0 +1,79 @@
+package gpm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// EntryMetadata describes how a store entry was installed. It is saved next to the entry directory,
+// as a hidden file named after the asset.
+type EntryMetadata struct {
+	// URL is the download URL of the asset on Github.
+	URL string `json:"url"`
+	// Source is the URL that served the asset, which differs from URL when a mirror served it.
+	Source string `json:"source,omitempty"`
+	// SHA256 is the digest of the downloaded asset, before extraction.
+	SHA256      string    `json:"sha256"`
+	Size        int64     `json:"size"`
+	InstalledAt time.Time `json:"installed_at"`
+	// VerifiedWith is what the digest of the asset was verified against, like a checksums asset of the
+	// release. It is empty when the asset was not verified.
+	VerifiedWith string `json:"verified_with,omitempty"`
+	// AssetID and AssetUpdatedAt identify the release asset on Github, to detect the assets of moving
+	// tags replaced since they were downloaded. See [IsMovingTag].
+	AssetID        int64     `json:"asset_id,omitempty"`
+	AssetUpdatedAt time.Time `json:"asset_updated_at"`
+}
+
+// EntryMetadataPath returns the path of the metadata of the store entry at entryPath.
+func EntryMetadataPath(entryPath string) string {
+	return filepath.Join(filepath.Dir(entryPath), "."+filepath.Base(entryPath)+".json")
+}
+
+// ReadEntryMetadata returns the metadata of the store entry at entryPath.
+func ReadEntryMetadata(entryPath string) (*EntryMetadata, error) {
+	metadataPath := EntryMetadataPath(entryPath)
+	data, err := os.ReadFile(metadataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+	var metadata EntryMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", metadataPath, err)
+	}
+	return &metadata, nil
+}
+
+// writeEntryMetadata saves the metadata of the store entry at entryPath.
+func writeEntryMetadata(entryPath string, metadata EntryMetadata) error {
+	metadataPath := EntryMetadataPath(entryPath)
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(metadataPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %q: %w", metadataPath, err)
+	}
+	return nil
+}
+
+// fileSHA256 returns the hex encoded SHA-256 digest and the size of the file at filePath.
+func fileSHA256(filePath string) (string, int64, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
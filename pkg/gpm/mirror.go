@@ -0,0 +1,130 @@
+package gpm
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Mirror serves URLs starting with Prefix from URL instead, like an artifact proxy of github.com.
+type Mirror struct {
+	Name   string `yaml:"name"`
+	Prefix string `yaml:"prefix"`
+	URL    string `yaml:"url"`
+	// Token is sent as a bearer token. Username and Password are sent with basic authentication.
+	// Environment variables like ${TOKEN} are expanded in credentials.
+	Token    string `yaml:"token,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+}
+
+// MirrorsConfig lists the mirrors tried in order, usually from the mirrors.yaml file of the config directory.
+type MirrorsConfig struct {
+	Mirrors []Mirror `yaml:"mirrors"`
+	// NoDirect disables falling back to the original URL when all the mirrors of a URL failed.
+	NoDirect bool `yaml:"no_direct"`
+}
+
+// WithMirrors makes requests go through mirrors. See [MirrorsConfig].
+func WithMirrors(config MirrorsConfig) GPMOption {
+	return func(gpm *GPM) {
+		gpm.mirrors = config
+	}
+}
+
+// LoadMirrors reads the mirrors.yaml file of the config directory at configPath. A missing file
+// configures no mirror. See [ConfigPath].
+func LoadMirrors(configPath string) (MirrorsConfig, error) {
+	var config MirrorsConfig
+	mirrorsPath := filepath.Join(configPath, "mirrors.yaml")
+	data, err := os.ReadFile(mirrorsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, fmt.Errorf("failed to read %q: %w", mirrorsPath, err)
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("failed to parse %q: %w", mirrorsPath, err)
+	}
+	for i, mirror := range config.Mirrors {
+		if mirror.Prefix == "" || mirror.URL == "" {
+			return config, fmt.Errorf("mirror #%d of %q needs a prefix and an url", i+1, mirrorsPath)
+		}
+	}
+	return config, nil
+}
+
+// mirrorTransport sends requests to the mirrors whose prefix matches their URL, in order, then to the
+// original URL unless [MirrorsConfig.NoDirect] is set. The next one is tried when a request fails or gets
+// an error status. The request of the returned response tells which one answered.
+type mirrorTransport struct {
+	base   http.RoundTripper
+	config MirrorsConfig
+}
+
+func (t mirrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	candidates := t.candidates(req)
+	var res *http.Response
+	var err error
+	for i, candidate := range candidates {
+		if i > 0 && req.GetBody != nil {
+			if candidate.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		res, err = t.base.RoundTrip(candidate)
+		if i == len(candidates)-1 || req.Context().Err() != nil || (req.Body != nil && req.GetBody == nil) {
+			break
+		}
+		if err == nil && res.StatusCode < http.StatusBadRequest {
+			break
+		}
+		if err != nil {
+			log.Printf("Failed to get %q, trying next source: %s", candidate.URL, err.Error())
+		} else {
+			log.Printf("Failed to get %q, trying next source: %s", candidate.URL, res.Status)
+			res.Body.Close()
+		}
+	}
+	return res, err
+}
+
+// candidates returns the requests to try for req: one per matching mirror, then req itself.
+func (t mirrorTransport) candidates(req *http.Request) []*http.Request {
+	var candidates []*http.Request
+	original := req.URL.String()
+	for _, mirror := range t.config.Mirrors {
+		if !strings.HasPrefix(original, mirror.Prefix) {
+			continue
+		}
+		u, err := url.Parse(mirror.URL + strings.TrimPrefix(original, mirror.Prefix))
+		if err != nil {
+			log.Printf("Skipping mirror %q: %s", mirror.Name, err.Error())
+			continue
+		}
+		candidate := req.Clone(req.Context())
+		candidate.URL = u
+		candidate.Host = ""
+		// Credentials of the original host must not leak to the mirror.
+		candidate.Header.Del("Authorization")
+		switch {
+		case mirror.Token != "":
+			candidate.Header.Set("Authorization", "Bearer "+os.ExpandEnv(mirror.Token))
+		case mirror.Username != "":
+			candidate.SetBasicAuth(os.ExpandEnv(mirror.Username), os.ExpandEnv(mirror.Password))
+		}
+		candidates = append(candidates, candidate)
+	}
+	if len(candidates) == 0 || !t.config.NoDirect {
+		candidates = append(candidates, req)
+	}
+	return candidates
+}
@@ -0,0 +1,81 @@
+package gpm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestMirrors(t *testing.T) {
+	var directRequests int
+	direct := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		directRequests++
+		w.Write([]byte("direct"))
+	}))
+	defer direct.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "ci" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("mirror " + r.URL.Path))
+	}))
+	defer mirror.Close()
+	t.Setenv("GPM_TEST_PASSWORD", "secret")
+
+	tests := []struct {
+		name     string
+		config   MirrorsConfig
+		path     string
+		want     string
+		wantErr  bool
+		wantHits int
+	}{
+		{"Fallback to next mirror", MirrorsConfig{Mirrors: []Mirror{
+			{Name: "broken", Prefix: direct.URL + "/releases/", URL: broken.URL + "/"},
+			{Name: "proxy", Prefix: direct.URL + "/releases/", URL: mirror.URL + "/github/", Username: "ci", Password: "${GPM_TEST_PASSWORD}"},
+		}}, "/releases/asset.tar.gz", "mirror /github/asset.tar.gz", false, 0},
+		{"Fallback to direct", MirrorsConfig{Mirrors: []Mirror{
+			{Name: "broken", Prefix: direct.URL + "/", URL: broken.URL + "/"},
+		}}, "/releases/asset.tar.gz", "direct", false, 1},
+		{"No direct", MirrorsConfig{Mirrors: []Mirror{
+			{Name: "broken", Prefix: direct.URL + "/", URL: broken.URL + "/"},
+		}, NoDirect: true}, "/releases/asset.tar.gz", "", true, 0},
+		{"No matching mirror", MirrorsConfig{Mirrors: []Mirror{
+			{Name: "broken", Prefix: "https://github.com/", URL: broken.URL + "/"},
+		}, NoDirect: true}, "/registry.yaml", "direct", false, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			directRequests = 0
+			gpm := NewGPM(WithMirrors(tt.config), WithRetryPolicy(RetryPolicy{Attempts: 1}))
+			got, err := gpm.Fetch(context.Background(), direct.URL+tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Fetch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("Fetch() = %q, want %q", got, tt.want)
+			}
+			if directRequests != tt.wantHits {
+				t.Errorf("Fetch() made %d direct requests, want %d", directRequests, tt.wantHits)
+			}
+		})
+	}
+
+	t.Run("Records source", func(t *testing.T) {
+		gpm := NewGPM(WithMirrors(tests[0].config), WithRetryPolicy(RetryPolicy{Attempts: 1}))
+		dst := filepath.Join(t.TempDir(), "asset.tar.gz")
+		if err := gpm.Download(context.Background(), direct.URL+"/releases/asset.tar.gz?token=x", dst, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := downloadSource(dst), mirror.URL+"/github/asset.tar.gz"; got != want {
+			t.Errorf("downloadSource() = %q, want %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package gpm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// ErrOffline is returned by requests made in offline mode.
+var ErrOffline = errors.New("network access disabled in offline mode")
+
+// ErrNotCached is returned when a dependency has to be installed from the store but was never downloaded.
+var ErrNotCached = errors.New("not found in the store")
+
+// WithOffline disables network access. Dependencies are then installed from the store only.
+func WithOffline(offline bool) GPMOption {
+	return func(gpm *GPM) {
+		gpm.offline = offline
+	}
+}
+
+// IsOffline reports whether network access is disabled. See [WithOffline].
+func (gpm GPM) IsOffline() bool {
+	return gpm.offline
+}
+
+// offlineTransport fails every request with [ErrOffline].
+type offlineTransport struct{}
+
+func (offlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, ErrOffline
+}
+
+// IsNetworkError reports whether err comes from failing to reach a server, rather than from its response.
+func IsNetworkError(err error) bool {
+	var urlErr *url.Error
+	var netErr net.Error
+	return !errors.Is(err, context.Canceled) && (errors.As(err, &urlErr) || errors.As(err, &netErr))
+}
+
+// fetchCached returns the store entry matching dep, with the paths of its executables, and with its entry
+// lock held. See [GPM.FindCachedDependency].
+func (gpm GPM) fetchCached(ctx context.Context, dep Dependency) (Dependency, map[string]string, func(), error) {
+	cached, err := gpm.FindCachedDependency(ctx, dep)
+	if err != nil {
+		return dep, nil, nil, err
+	}
+	unlock, err := gpm.lockAsset(ctx, cached)
+	if err != nil {
+		return dep, nil, nil, err
+	}
+	notifyState(ctx, StateInstalling)
+	cached.Package = gpm.packageOf(ctx, cached)
+	log.Printf("Installing %q from the store", cached)
+	executables, err := gpm.entryExecutables(cached)
+	if err != nil {
+		unlock()
+		return dep, nil, nil, err
+	}
+	return cached, executables, unlock, nil
+}
+
+// fallbackCached takes dep from the store after err, a network error, prevented to resolve it online.
+func (gpm GPM) fallbackCached(ctx context.Context, dep Dependency, err error) (Dependency, map[string]string, func(), error) {
+	cached, executables, unlock, cachedErr := gpm.fetchCached(ctx, dep)
+	if cachedErr != nil {
+		return dep, nil, nil, fmt.Errorf("%w (and %s)", err, cachedErr.Error())
+	}
+	log.Printf("Network unavailable (%s), installed %q from the store", err.Error(), cached)
+	return cached, executables, unlock, nil
+}
@@ -0,0 +1,69 @@
+package gpm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type unreachableTransport struct{}
+
+func (unreachableTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("network is unreachable")
+}
+
+func TestInstallDependency_FromStore(t *testing.T) {
+	tmp := t.TempDir()
+	storePath := filepath.Join(tmp, "store")
+	dir := filepath.Join(storePath, "github.com", "owner", "tool", "v1.0.0", "tool_linux_amd64")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tool"), []byte("v1.0.0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	opts := []GPMOption{
+		WithStorePath(storePath),
+		WithBinPath(filepath.Join(tmp, "bin")),
+		WithConfigPath(filepath.Join(tmp, "config")),
+		WithRetryPolicy(RetryPolicy{Attempts: 1}),
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		opts    []GPMOption
+		dep     Dependency
+		wantTag string
+		wantErr error
+	}{
+		{"Offline latest", []GPMOption{WithOffline(true)}, Dependency{Repo: "tool"}, "v1.0.0", nil},
+		{"Offline not cached", []GPMOption{WithOffline(true)}, Dependency{Owner: "owner", Repo: "tool", ReleaseTag: "v2.0.0"}, "", ErrNotCached},
+		{"Cached tag", []GPMOption{WithHTTPClient(&http.Client{Transport: unreachableTransport{}})}, Dependency{Owner: "owner", Repo: "tool", ReleaseTag: "1.0.0"}, "v1.0.0", nil},
+		{"Network fallback", []GPMOption{WithHTTPClient(&http.Client{Transport: unreachableTransport{}})}, Dependency{Owner: "owner", Repo: "tool"}, "v1.0.0", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gpm := NewGPM(append(opts, tt.opts...)...)
+			installed, err := gpm.InstallDependency(ctx, tt.dep, nil)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("InstallDependency() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("InstallDependency() error = %v", err)
+			}
+			if installed.ReleaseTag != tt.wantTag || installed.Owner != "owner" {
+				t.Errorf("InstallDependency() = %v, want owner/tool@%s", installed, tt.wantTag)
+			}
+			if _, err := os.Stat(filepath.Join(tmp, "bin", "tool")); err != nil {
+				t.Errorf("InstallDependency() did not link: %v", err)
+			}
+		})
+	}
+}
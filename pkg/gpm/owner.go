@@ -0,0 +1,170 @@
+package gpm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v47/github"
+)
+
+// ErrAmbiguousOwner is returned when a repository name matches several owners and no [OwnerChooser] is set.
+var ErrAmbiguousOwner = errors.New("ambiguous repository owner")
+
+// OwnerChooser picks the repository to install among candidates sharing the same name,
+// sorted by decreasing stars.
+type OwnerChooser func(repo string, candidates []*github.Repository) (*github.Repository, error)
+
+// WithOwnerChooser sets the function called when the owner of a repository cannot be resolved
+// unambiguously. Without it, resolution fails with [ErrAmbiguousOwner].
+func WithOwnerChooser(choose OwnerChooser) GPMOption {
+	return func(gpm *GPM) {
+		gpm.ownerChooser = choose
+	}
+}
+
+// GetAliasesPath returns the path of the local alias registry mapping repositories names to their owner.
+func (gpm GPM) GetAliasesPath() (string, error) {
+	storePath, err := gpm.GetStorePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(storePath, "aliases.json"), nil
+}
+
+// LoadAliases reads the local alias registry. A missing registry is empty.
+func (gpm GPM) LoadAliases() (map[string]string, error) {
+	aliasesPath, err := gpm.GetAliasesPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get aliases path: %w", err)
+	}
+	aliases := map[string]string{}
+	data, err := os.ReadFile(aliasesPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return aliases, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", aliasesPath, err)
+	}
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", aliasesPath, err)
+	}
+	return aliases, nil
+}
+
+// SaveAlias records owner as the owner of repo in the local alias registry.
+func (gpm GPM) SaveAlias(repo, owner string) error {
+	aliases, err := gpm.LoadAliases()
+	if err != nil {
+		return err
+	}
+	aliases[strings.ToLower(repo)] = owner
+	aliasesPath, err := gpm.GetAliasesPath()
+	if err != nil {
+		return fmt.Errorf("failed to get aliases path: %w", err)
+	}
+	data, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(aliasesPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %q: %w", filepath.Dir(aliasesPath), err)
+	}
+	if err := os.WriteFile(aliasesPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %q: %w", aliasesPath, err)
+	}
+	return nil
+}
+
+// ResolveOwner finds the owner of a repository known only by its name. The local alias registry is
+// consulted first, then Github repositories with this exact name are ranked by stars. The most starred
+// one is picked if it is the only one or has at least 10 times more stars than the next, counted as one
+// star when it has none, otherwise the [OwnerChooser] decides. The resolved owner is recorded in the
+// alias registry, and in the lock file by gpm install.
+func (gpm GPM) ResolveOwner(ctx context.Context, repo string) (string, error) {
+	aliases, err := gpm.LoadAliases()
+	if err != nil {
+		return "", err
+	}
+	if owner, ok := aliases[strings.ToLower(repo)]; ok {
+		return owner, nil
+	}
+
+	repositories, err := gpm.SearchRepositories(ctx, repo+" in:name")
+	if err != nil {
+		return "", err
+	}
+	candidates := make([]*github.Repository, 0, len(repositories))
+	for _, repository := range repositories {
+		if strings.EqualFold(repository.GetName(), repo) {
+			candidates = append(candidates, repository)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].GetStargazersCount() > candidates[j].GetStargazersCount()
+	})
+
+	var chosen *github.Repository
+	switch {
+	case len(candidates) == 0:
+		return "", fmt.Errorf("no Github repository named %q found", repo)
+	case len(candidates) == 1 || isDominant(candidates[0], candidates[1]):
+		chosen = candidates[0]
+	case gpm.ownerChooser == nil:
+		names := make([]string, 0, len(candidates))
+		for _, candidate := range candidates {
+			names = append(names, candidate.GetFullName())
+		}
+		return "", fmt.Errorf("%w: %q could be any of %s", ErrAmbiguousOwner, repo, strings.Join(names, ", "))
+	default:
+		chosen, err = gpm.ownerChooser(repo, candidates)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	owner := chosen.GetOwner().GetLogin()
+	if err := gpm.SaveAlias(repo, owner); err != nil {
+		log.Printf("Failed to record alias %s/%s: %s", owner, repo, err.Error())
+	}
+	return owner, nil
+}
+
+// isDominant reports whether first has at least 10 times more stars than second, so that a repository
+// without stars is not enough to pick another without stars either.
+func isDominant(first, second *github.Repository) bool {
+	stars := second.GetStargazersCount()
+	if stars < 1 {
+		stars = 1
+	}
+	return first.GetStargazersCount() >= 10*stars
+}
+
+// ResolveDependencies fills in the owner of dependencies that lack one. See [GPM.ResolveOwner].
+// When the network is unreachable, owners that are not in the alias registry are left empty, to be
+// found in the store.
+func (gpm GPM) ResolveDependencies(ctx context.Context, deps []Dependency) ([]Dependency, error) {
+	resolved := make([]Dependency, 0, len(deps))
+	for _, dep := range deps {
+		if dep.Owner == "" {
+			owner, err := gpm.ResolveOwner(ctx, dep.Repo)
+			if err != nil && IsNetworkError(err) && ctx.Err() == nil {
+				log.Printf("Failed to resolve owner of %q: %s", dep, err.Error())
+				resolved = append(resolved, dep)
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve owner of %q: %w", dep, err)
+			}
+			dep.Owner = owner
+		}
+		resolved = append(resolved, dep)
+	}
+	return resolved, nil
+}
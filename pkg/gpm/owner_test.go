@@ -0,0 +1,121 @@
+package gpm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v47/github"
+)
+
+func TestResolveOwner(t *testing.T) {
+	// Repositories found by name, as owner:stars.
+	repositories := map[string][]string{
+		"unique":    {"alice:3"},
+		"dominant":  {"bob:10", "carol:1"},
+		"starless":  {"dave:0", "erin:0"},
+		"few":       {"frank:9", "grace:0"},
+		"close":     {"heidi:120", "ivan:40"},
+		"substring": {"judy:5"},
+	}
+	searches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search/repositories" {
+			http.NotFound(w, r)
+			return
+		}
+		searches++
+		repo := strings.TrimSuffix(r.URL.Query().Get("q"), " in:name")
+		items := []map[string]any{}
+		for _, candidate := range repositories[repo] {
+			owner, stars, _ := strings.Cut(candidate, ":")
+			name := repo
+			if repo == "substring" {
+				name = "substring-tool"
+			}
+			items = append(items, map[string]any{
+				"name":             name,
+				"full_name":        owner + "/" + name,
+				"owner":            map[string]string{"login": owner},
+				"stargazers_count": json.Number(stars),
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"items": items})
+	}))
+	defer server.Close()
+
+	storePath := filepath.Join(t.TempDir(), "store")
+	newGPM := func(options ...GPMOption) *GPM {
+		return NewGPM(append([]GPMOption{
+			WithStorePath(storePath),
+			WithHTTPClient(&http.Client{Transport: serverTransport{server}}),
+			WithHTTPCache(false),
+			WithRetryPolicy(RetryPolicy{Attempts: 1}),
+		}, options...)...)
+	}
+	chooseLast := WithOwnerChooser(func(repo string, candidates []*github.Repository) (*github.Repository, error) {
+		return candidates[len(candidates)-1], nil
+	})
+
+	tests := []struct {
+		name    string
+		repo    string
+		options []GPMOption
+		want    string
+		wantErr bool
+		// wantIs is the error that the error must wrap, if any.
+		wantIs error
+	}{
+		{"Unique", "unique", nil, "alice", false, nil},
+		{"Ten times more stars", "dominant", nil, "bob", false, nil},
+		{"No stars", "starless", nil, "", true, ErrAmbiguousOwner},
+		{"Few stars", "few", nil, "", true, ErrAmbiguousOwner},
+		{"Close stars", "close", nil, "", true, ErrAmbiguousOwner},
+		{"Chooser", "close", []GPMOption{chooseLast}, "ivan", false, nil},
+		{"Recorded alias", "CLOSE", nil, "ivan", false, nil},
+		{"Not found", "substring", nil, "", true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, err := newGPM(tt.options...).ResolveOwner(context.Background(), tt.repo)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveOwner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Errorf("ResolveOwner() error = %v, want %v", err, tt.wantIs)
+			}
+			if owner != tt.want {
+				t.Errorf("ResolveOwner() = %q, want %q", owner, tt.want)
+			}
+		})
+	}
+
+	// Aliases are looked up without searching Github.
+	searches = 0
+	if owner, err := newGPM().ResolveOwner(context.Background(), "unique"); err != nil || owner != "alice" || searches != 0 {
+		t.Errorf("ResolveOwner() = %q, %v after %d searches, want alice from the aliases", owner, err, searches)
+	}
+	data, err := os.ReadFile(filepath.Join(storePath, "aliases.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var aliases map[string]string
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"unique": "alice", "dominant": "bob", "close": "ivan"}
+	if len(aliases) != len(want) {
+		t.Errorf("aliases.json = %v, want %v", aliases, want)
+	}
+	for repo, owner := range want {
+		if aliases[repo] != owner {
+			t.Errorf("aliases.json maps %q to %q, want %q", repo, aliases[repo], owner)
+		}
+	}
+}
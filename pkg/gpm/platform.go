@@ -0,0 +1,140 @@
+package gpm
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// Platform is an OS/architecture pair using Go names (eg. linux/amd64).
+type Platform struct {
+	OS   string
+	Arch string
+}
+
+// CurrentPlatform returns the platform gpm is running on.
+func CurrentPlatform() Platform {
+	return Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+}
+
+func (p Platform) String() string {
+	return p.OS + "/" + p.Arch
+}
+
+// ParsePlatform parses a platform formatted like OS/ARCH (eg. linux/amd64).
+func ParsePlatform(s string) (Platform, error) {
+	goos, goarch, ok := strings.Cut(s, "/")
+	if !ok || goos == "" || goarch == "" || strings.Contains(goarch, "/") {
+		return Platform{}, fmt.Errorf("invalid platform %q, expected OS/ARCH like linux/amd64", s)
+	}
+	return Platform{OS: goos, Arch: goarch}, nil
+}
+
+// Spellings of GOOS and GOARCH values commonly found in release asset names.
+// Architectures are tried in order so that "x86_64" is never taken for "x86".
+var (
+	osAliases = []struct {
+		goos    string
+		aliases []string
+	}{
+		{"linux", []string{"linux"}},
+		{"darwin", []string{"darwin", "macos", "osx", "apple", "mac"}},
+		{"windows", []string{"windows", "win64", "win32", "win"}},
+		{"freebsd", []string{"freebsd"}},
+	}
+	archAliases = []struct {
+		goarch  string
+		aliases []string
+	}{
+		{"amd64", []string{"amd64", "x86_64", "x86-64", "x64", "64bit"}},
+		{"arm64", []string{"arm64", "aarch64", "armv8"}},
+		{"386", []string{"386", "i386", "i686", "x86", "32bit"}},
+		{"arm", []string{"armv7", "armv7l", "armv6", "armhf", "arm"}},
+	}
+	universalAliases = []string{"universal", "all"}
+
+	// Assets that accompany binaries rather than being installable themselves.
+	regexpAuxiliaryAsset = regexp.MustCompile(`(?i)\.(sha\d*(sum)?|md5|sig|asc|pem|crt|cert|sbom|spdx|json|txt|deb|rpm|apk|msi|pkg|dmg)$|checksums?`)
+)
+
+// containsWord reports whether word appears in s delimited by non alphanumeric characters.
+func containsWord(s, word string) bool {
+	for start := 0; start < len(s); {
+		i := strings.Index(s[start:], word)
+		if i == -1 {
+			return false
+		}
+		i += start
+		end := i + len(word)
+		if (i == 0 || !isAlnum(s[i-1])) && (end == len(s) || !isAlnum(s[end])) {
+			return true
+		}
+		start = i + 1
+	}
+	return false
+}
+
+func isAlnum(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('0' <= c && c <= '9')
+}
+
+// DetectPlatform guesses the platform an asset targets from its name.
+// Unknown components are returned empty.
+func DetectPlatform(assetName string) Platform {
+	name := strings.ToLower(assetName)
+	var p Platform
+	for _, os := range osAliases {
+		for _, alias := range os.aliases {
+			if containsWord(name, alias) {
+				p.OS = os.goos
+				break
+			}
+		}
+		if p.OS != "" {
+			break
+		}
+	}
+	for _, arch := range archAliases {
+		for _, alias := range arch.aliases {
+			if containsWord(name, alias) {
+				p.Arch = arch.goarch
+				break
+			}
+		}
+		if p.Arch != "" {
+			break
+		}
+	}
+	if p.Arch == "" && p.OS == "darwin" {
+		for _, alias := range universalAliases {
+			if containsWord(name, alias) {
+				p.Arch = "universal"
+			}
+		}
+	}
+	return p
+}
+
+// MatchPlatform reports whether the asset is an installable artifact built for platform.
+func MatchPlatform(assetName string, platform Platform) bool {
+	if regexpAuxiliaryAsset.MatchString(assetName) {
+		return false
+	}
+	detected := DetectPlatform(assetName)
+	if detected.OS != platform.OS {
+		return false
+	}
+	return detected.Arch == platform.Arch || detected.Arch == "universal" || (detected.Arch == "" && platform.Arch == "amd64")
+}
+
+// PlatformAssets filters assetsNames with [MatchPlatform], preserving order.
+func PlatformAssets(assetsNames []string, platform Platform) []string {
+	var matches []string
+	for _, name := range assetsNames {
+		if MatchPlatform(name, platform) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
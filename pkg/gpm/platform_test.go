@@ -0,0 +1,32 @@
+package gpm
+
+import "testing"
+
+func TestMatchPlatform(t *testing.T) {
+	linuxAmd64 := Platform{OS: "linux", Arch: "amd64"}
+	darwinArm64 := Platform{OS: "darwin", Arch: "arm64"}
+	tests := []struct {
+		name      string
+		assetName string
+		platform  Platform
+		want      bool
+	}{
+		{"x86_64 musl", "ripgrep-14.1.0-x86_64-unknown-linux-musl.tar.gz", linuxAmd64, true},
+		{"Go naming", "gh_2.40.0_linux_amd64.tar.gz", linuxAmd64, true},
+		{"Other arch", "gh_2.40.0_linux_arm64.tar.gz", linuxAmd64, false},
+		{"x86 is not x86_64", "tool-linux-x86.tar.gz", linuxAmd64, false},
+		{"Checksum", "gh_2.40.0_linux_amd64.tar.gz.sha256", linuxAmd64, false},
+		{"Debian package", "gh_2.40.0_linux_amd64.deb", linuxAmd64, false},
+		{"aarch64 apple", "ripgrep-14.1.0-aarch64-apple-darwin.tar.gz", darwinArm64, true},
+		{"macOS universal", "tool_macOS_universal.zip", darwinArm64, true},
+		{"No arch defaults to amd64", "tool-linux.tar.gz", linuxAmd64, true},
+		{"Windows", "tool-windows-amd64.zip", linuxAmd64, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchPlatform(tt.assetName, tt.platform); got != tt.want {
+				t.Errorf("MatchPlatform(%q, %s) = %v, want %v", tt.assetName, tt.platform, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package gpm
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ManifestFileName is the name of the manifest that marks the root of a project.
+const ManifestFileName = "gpm.yaml"
+
+// FindManifest looks for a file named name in dir and its parents, and returns its path.
+// It returns an error wrapping [os.ErrNotExist] if none is found.
+func FindManifest(dir, name string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		manifestPath := filepath.Join(dir, name)
+		if fileInfo, err := os.Stat(manifestPath); err == nil && fileInfo.Mode().IsRegular() {
+			return manifestPath, nil
+		} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("failed to stat %q: %w", manifestPath, err)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no %s found: %w", name, os.ErrNotExist)
+		}
+		dir = parent
+	}
+}
+
+// ProjectBinPath returns the bin directory of the project whose manifest is at manifestPath.
+func ProjectBinPath(manifestPath string) string {
+	return filepath.Join(filepath.Dir(manifestPath), ".gpm", "bin")
+}
+
+// SwapPathDir returns the directories of a PATH environment variable with oldDir removed
+// and newDir prepended. Empty oldDir or newDir are ignored.
+func SwapPathDir(pathEnv, oldDir, newDir string) []string {
+	var dirs []string
+	if newDir != "" {
+		dirs = append(dirs, newDir)
+	}
+	for _, dir := range filepath.SplitList(pathEnv) {
+		if dir == "" || (oldDir != "" && dir == oldDir) || dir == newDir {
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
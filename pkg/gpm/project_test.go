@@ -0,0 +1,85 @@
+package gpm
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindManifest(t *testing.T) {
+	tmp := t.TempDir()
+	for _, dir := range []string{"outer/inner/src", "outer/other", "outer/inner/dir/gpm.yaml"} {
+		if err := os.MkdirAll(filepath.Join(tmp, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, manifestPath := range []string{"outer/gpm.yaml", "outer/inner/gpm.yaml"} {
+		if err := os.WriteFile(filepath.Join(tmp, manifestPath), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A name that no parent of tmp holds, to reach the filesystem root.
+	missing := "gpm-" + filepath.Base(tmp) + ".yaml"
+
+	tests := []struct {
+		name         string
+		dir          string
+		manifestName string
+		want         string
+		wantNotExist bool
+	}{
+		{"Same directory", filepath.Join(tmp, "outer"), ManifestFileName, "outer/gpm.yaml", false},
+		{"Parent", filepath.Join(tmp, "outer/other"), ManifestFileName, "outer/gpm.yaml", false},
+		{"Nested project", filepath.Join(tmp, "outer/inner/src"), ManifestFileName, "outer/inner/gpm.yaml", false},
+		{"Directory named like the manifest", filepath.Join(tmp, "outer/inner/dir"), ManifestFileName, "outer/inner/gpm.yaml", false},
+		{"Not found up to the root", filepath.Join(tmp, "outer/inner/src"), missing, "", true},
+		{"Filesystem root", "/", missing, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindManifest(tt.dir, tt.manifestName)
+			if tt.wantNotExist {
+				if !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("FindManifest() = %q, %v, want an error wrapping os.ErrNotExist", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := filepath.Join(tmp, tt.want); got != want {
+				t.Errorf("FindManifest() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestSwapPathDir(t *testing.T) {
+	join := func(dirs ...string) string { return strings.Join(dirs, string(filepath.ListSeparator)) }
+	tests := []struct {
+		name    string
+		pathEnv string
+		oldDir  string
+		newDir  string
+		want    []string
+	}{
+		{"Prepend", join("/usr/bin", "/bin"), "", "/p/.gpm/bin", []string{"/p/.gpm/bin", "/usr/bin", "/bin"}},
+		{"Swap", join("/a/.gpm/bin", "/usr/bin"), "/a/.gpm/bin", "/b/.gpm/bin", []string{"/b/.gpm/bin", "/usr/bin"}},
+		{"Remove only", join("/a/.gpm/bin", "/usr/bin"), "/a/.gpm/bin", "", []string{"/usr/bin"}},
+		{"Duplicate old dir", join("/a/.gpm/bin", "/usr/bin", "/a/.gpm/bin"), "/a/.gpm/bin", "", []string{"/usr/bin"}},
+		{"Duplicate new dir", join("/usr/bin", "/b/.gpm/bin", "/bin", "/b/.gpm/bin"), "", "/b/.gpm/bin", []string{"/b/.gpm/bin", "/usr/bin", "/bin"}},
+		{"Duplicate other dir", join("/usr/bin", "/usr/bin"), "", "", []string{"/usr/bin", "/usr/bin"}},
+		{"Empty entries", join("", "/usr/bin", ""), "", "/p/.gpm/bin", []string{"/p/.gpm/bin", "/usr/bin"}},
+		{"Empty PATH", "", "/a/.gpm/bin", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SwapPathDir(tt.pathEnv, tt.oldDir, tt.newDir); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SwapPathDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
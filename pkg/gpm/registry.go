@@ -0,0 +1,277 @@
+package gpm
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RegistryVersion is the version of the registry format understood by gpm.
+const RegistryVersion = 1
+
+// Registry is an index of packages installable by a short name (eg. `gpm install rg`).
+// Registries are YAML or JSON documents.
+type Registry struct {
+	Version  int                 `yaml:"version" json:"version"`
+	Packages map[string]*Package `yaml:"packages" json:"packages"`
+	// Source is where the registry was loaded from.
+	Source string `yaml:"-" json:"-"`
+}
+
+// Package is a registry recipe describing how to install a Github repository release.
+type Package struct {
+	Name        string `yaml:"-" json:"-"`
+	Repo        string `yaml:"repo" json:"repo"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Aliases are other names the package is known by, like the names used by other tool managers.
+	Aliases []string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+	// Assets maps platforms (eg. linux/amd64, or only an OS like linux) to glob patterns of release
+	// asset names. Platforms that are not listed fall back to [PlatformAssets].
+	Assets map[string]string `yaml:"assets,omitempty" json:"assets,omitempty"`
+	// Bin maps executables names to create in the bin directory to glob patterns of paths inside the
+	// downloaded asset.
+	Bin map[string]string `yaml:"bin,omitempty" json:"bin,omitempty"`
+	// Checksum is a glob pattern of the release asset holding the SHA checksums of the other assets,
+	// where {asset} is replaced by the name of the downloaded asset (eg. {asset}.sha256).
+	Checksum string `yaml:"checksum,omitempty" json:"checksum,omitempty"`
+	// MovingTags are release tags whose assets are replaced in place, in addition to [DefaultMovingTags].
+	MovingTags []string `yaml:"moving_tags,omitempty" json:"moving_tags,omitempty"`
+	// Test is run after the executables are linked, and they are unlinked if it fails. In its command and
+	// in PostInstall, {bin} is replaced by the linked executable named like the repository, or else the
+	// only executable, {bin:NAME} by the linked executable NAME, and {bin_dir} by the bin directory.
+	Test *SmokeTest `yaml:"test,omitempty" json:"test,omitempty"`
+	// PostInstall are commands run after Test. The executables are unlinked if one of them fails.
+	// Test and PostInstall are only run by [GPM.InstallDependency], not when executables are linked again
+	// by [GPM.Use], [GPM.Rollback] or [GPM.InstallBundle]. See [WithRemoteHooks].
+	PostInstall []string `yaml:"post_install,omitempty" json:"post_install,omitempty"`
+	// Source is where the registry of the package was loaded from.
+	Source string `yaml:"-" json:"-"`
+}
+
+// OwnerRepo splits [Package.Repo] in its owner and repository parts.
+func (pkg Package) OwnerRepo() (string, string) {
+	owner, repo, _ := strings.Cut(pkg.Repo, "/")
+	return owner, repo
+}
+
+// AssetPattern returns the asset name pattern for platform, if any.
+func (pkg Package) AssetPattern(platform Platform) (string, bool) {
+	if pattern, ok := pkg.Assets[platform.String()]; ok {
+		return pattern, true
+	}
+	pattern, ok := pkg.Assets[platform.OS]
+	return pattern, ok
+}
+
+// ChecksumAsset returns the name of the asset among assetsNames holding the checksum of assetName.
+func (pkg Package) ChecksumAsset(assetName string, assetsNames []string) (string, bool) {
+	if pkg.Checksum == "" {
+		return "", false
+	}
+	pattern := strings.ReplaceAll(pkg.Checksum, "{asset}", assetName)
+	return MatchAsset(pattern, assetsNames)
+}
+
+// MatchAsset returns the first name of assetsNames matching the glob pattern.
+func MatchAsset(pattern string, assetsNames []string) (string, bool) {
+	for _, name := range assetsNames {
+		if ok, _ := path.Match(pattern, name); ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+//go:embed registry.yaml
+var defaultRegistry []byte
+
+// ParseRegistry decodes a YAML or JSON registry.
+func ParseRegistry(data []byte, source string) (*Registry, error) {
+	var registry Registry
+	if err := yaml.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("failed to parse registry %q: %w", source, err)
+	}
+	if registry.Version != RegistryVersion {
+		return nil, fmt.Errorf("registry %q has unsupported version %d (want %d)", source, registry.Version, RegistryVersion)
+	}
+	for name, pkg := range registry.Packages {
+		if owner, repo := pkg.OwnerRepo(); owner == "" || repo == "" {
+			return nil, fmt.Errorf("package %q of registry %q must have repo in the form OWNER/REPOSITORY", name, source)
+		}
+		pkg.Name = name
+		pkg.Source = source
+	}
+	registry.Source = source
+	return &registry, nil
+}
+
+// WithRegistries adds registries sources, as file paths or HTTP URLs, that take precedence over
+// the registries found in the config directory and the registry embedded in gpm.
+func WithRegistries(sources ...string) GPMOption {
+	return func(gpm *GPM) {
+		gpm.registries = append(gpm.registries, sources...)
+	}
+}
+
+// LoadRegistries returns registries by decreasing priority: sources set with [WithRegistries], files in
+// the registries directory of the config directory, then the embedded registry.
+func (gpm GPM) LoadRegistries(ctx context.Context) ([]*Registry, error) {
+	return gpm.loadRegistries(ctx, false)
+}
+
+// LoadLocalRegistries returns the registries of [GPM.LoadRegistries] that are read without network
+// access. Registries at HTTP URLs are skipped.
+func (gpm GPM) LoadLocalRegistries() ([]*Registry, error) {
+	return gpm.loadRegistries(context.Background(), true)
+}
+
+func (gpm GPM) loadRegistries(ctx context.Context, local bool) ([]*Registry, error) {
+	var sources []string
+	for _, source := range gpm.registries {
+		if !local || !isURL(source) {
+			sources = append(sources, source)
+		}
+	}
+	configPath, err := gpm.GetConfigPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config path: %w", err)
+	}
+	registriesPath := filepath.Join(configPath, "registries")
+	dirEntries, err := os.ReadDir(registriesPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read directory %q: %w", registriesPath, err)
+	}
+	var files []string
+	for _, dirEntry := range dirEntries {
+		switch filepath.Ext(dirEntry.Name()) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, filepath.Join(registriesPath, dirEntry.Name()))
+		}
+	}
+	sort.Strings(files)
+	sources = append(sources, files...)
+
+	registries := make([]*Registry, 0, len(sources)+1)
+	for _, source := range sources {
+		data, err := gpm.readRegistry(ctx, source)
+		if err != nil && IsNetworkError(err) && ctx.Err() == nil {
+			log.Printf("Skipping registry %q: %s", source, err.Error())
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		registry, err := ParseRegistry(data, source)
+		if err != nil {
+			return nil, err
+		}
+		registries = append(registries, registry)
+	}
+	registry, err := ParseRegistry(defaultRegistry, "embedded")
+	if err != nil {
+		return nil, err
+	}
+	return append(registries, registry), nil
+}
+
+func (gpm GPM) readRegistry(ctx context.Context, source string) ([]byte, error) {
+	if !isURL(source) {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read registry %q: %w", source, err)
+		}
+		return data, nil
+	}
+	return gpm.Fetch(ctx, source)
+}
+
+// isURL reports whether the registry source is an HTTP URL rather than a file path.
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// LookupPackage returns the package named name from the registry with the highest priority,
+// or nil if no registry knows it.
+func (gpm GPM) LookupPackage(ctx context.Context, name string) (*Package, error) {
+	registries, err := gpm.LoadRegistries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return lookupPackage(registries, name), nil
+}
+
+func lookupPackage(registries []*Registry, name string) *Package {
+	for _, registry := range registries {
+		if pkg, ok := registry.Packages[name]; ok {
+			return pkg
+		}
+	}
+	for _, registry := range registries {
+		for _, pkgName := range sortedKeys(registry.Packages) {
+			for _, alias := range registry.Packages[pkgName].Aliases {
+				if alias == name {
+					return registry.Packages[pkgName]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// lookupPackageByRepo returns the package installing the Github repository OWNER/REPOSITORY.
+func lookupPackageByRepo(registries []*Registry, repo string) *Package {
+	for _, registry := range registries {
+		for _, pkgName := range sortedKeys(registry.Packages) {
+			if pkg := registry.Packages[pkgName]; strings.EqualFold(pkg.Repo, repo) {
+				return pkg
+			}
+		}
+	}
+	return nil
+}
+
+// ConvertDependenciesStrings parses raw strings like [ConvertDependenciesStrings] and resolves dependencies
+// without owner that name a registry package.
+func (gpm GPM) ConvertDependenciesStrings(ctx context.Context, s ...string) ([]Dependency, error) {
+	return convertDependenciesStrings(s, func() ([]*Registry, error) { return gpm.LoadRegistries(ctx) })
+}
+
+// ConvertLocalDependenciesStrings is [GPM.ConvertDependenciesStrings] looking packages up in the
+// registries read without network access only. See [GPM.LoadLocalRegistries].
+func (gpm GPM) ConvertLocalDependenciesStrings(s ...string) ([]Dependency, error) {
+	return convertDependenciesStrings(s, gpm.LoadLocalRegistries)
+}
+
+// convertDependenciesStrings parses s, resolving dependencies without owner that name a package of the
+// registries returned by loadRegistries, which is only called if needed.
+func convertDependenciesStrings(s []string, loadRegistries func() ([]*Registry, error)) ([]Dependency, error) {
+	deps, err := ConvertDependenciesStrings(s...)
+	if err != nil {
+		return nil, err
+	}
+	var registries []*Registry
+	for i, dep := range deps {
+		if dep.Owner != "" {
+			continue
+		}
+		if registries == nil {
+			if registries, err = loadRegistries(); err != nil {
+				return nil, err
+			}
+		}
+		if pkg := lookupPackage(registries, dep.Repo); pkg != nil {
+			deps[i].Owner, deps[i].Repo = pkg.OwnerRepo()
+			deps[i].Package = pkg
+		}
+	}
+	return deps, nil
+}
@@ -0,0 +1,71 @@
+package gpm
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v47/github"
+)
+
+func TestParseRegistry_Embedded(t *testing.T) {
+	registry, err := ParseRegistry(defaultRegistry, "embedded")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rg, ok := registry.Packages["rg"]
+	if !ok {
+		t.Fatal("embedded registry is missing rg")
+	}
+	if owner, repo := rg.OwnerRepo(); owner != "BurntSushi" || repo != "ripgrep" {
+		t.Errorf("rg.OwnerRepo() = %s, %s, want BurntSushi, ripgrep", owner, repo)
+	}
+}
+
+func TestParseRegistry_Version(t *testing.T) {
+	if _, err := ParseRegistry([]byte(`{"version": 2, "packages": {}}`), "test"); err == nil {
+		t.Error("ParseRegistry() accepted an unsupported version")
+	}
+}
+
+func TestSelectAsset(t *testing.T) {
+	release := &github.RepositoryRelease{TagName: github.String("14.1.0")}
+	for _, name := range []string{
+		"ripgrep-14.1.0-x86_64-unknown-linux-musl.tar.gz",
+		"ripgrep-14.1.0-x86_64-unknown-linux-musl.tar.gz.sha256",
+		"ripgrep-14.1.0-aarch64-apple-darwin.tar.gz",
+		"ripgrep_14.1.0-1_amd64.deb",
+	} {
+		release.Assets = append(release.Assets, &github.ReleaseAsset{Name: github.String(name)})
+	}
+	pkg := &Package{
+		Repo:     "BurntSushi/ripgrep",
+		Assets:   map[string]string{"darwin": "ripgrep-*-apple-darwin.tar.gz"},
+		Checksum: "{asset}.sha256",
+	}
+	tests := []struct {
+		name     string
+		dep      Dependency
+		platform Platform
+		want     string
+	}{
+		{"Explicit asset", Dependency{AssetName: "ripgrep_14.1.0-1_amd64.deb"}, Platform{"linux", "amd64"}, "ripgrep_14.1.0-1_amd64.deb"},
+		{"Package pattern", Dependency{Package: pkg}, Platform{"darwin", "arm64"}, "ripgrep-14.1.0-aarch64-apple-darwin.tar.gz"},
+		{"Platform detection", Dependency{Package: pkg}, Platform{"linux", "amd64"}, "ripgrep-14.1.0-x86_64-unknown-linux-musl.tar.gz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asset, err := SelectAsset(release, tt.dep, tt.platform)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := asset.GetName(); got != tt.want {
+				t.Errorf("SelectAsset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if checksum, _ := pkg.ChecksumAsset("ripgrep-14.1.0-x86_64-unknown-linux-musl.tar.gz", AssetsNames(release)); checksum != "ripgrep-14.1.0-x86_64-unknown-linux-musl.tar.gz.sha256" {
+		t.Errorf("ChecksumAsset() = %v", checksum)
+	}
+	if _, err := SelectAsset(release, Dependency{}, Platform{"windows", "amd64"}); err == nil {
+		t.Error("SelectAsset() found an asset for windows")
+	}
+}
@@ -0,0 +1,242 @@
+package gpm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures how failed requests to Github are retried.
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts of a request, including the first one.
+	Attempts int
+	// MinBackoff and MaxBackoff bound the exponential backoff between attempts, before jitter.
+	MinBackoff, MaxBackoff time.Duration
+	// Timeout bounds the time to wait for response headers of each attempt. Zero means no timeout.
+	Timeout time.Duration
+	// MaxWait is the longest wait accepted for a rate limit to reset. Longer waits fail immediately.
+	MaxWait time.Duration
+}
+
+// DefaultRetryPolicy is the [RetryPolicy] used unless [WithRetryPolicy] is set.
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:   5,
+	MinBackoff: time.Second,
+	MaxBackoff: 30 * time.Second,
+	Timeout:    30 * time.Second,
+	MaxWait:    15 * time.Minute,
+}
+
+// WithRetryPolicy sets how failed requests are retried. See [DefaultRetryPolicy].
+func WithRetryPolicy(policy RetryPolicy) GPMOption {
+	return func(gpm *GPM) {
+		gpm.retryPolicy = policy
+	}
+}
+
+// RetryEvent describes a wait before retrying a request.
+type RetryEvent struct {
+	// Attempt is the number of the attempt that failed.
+	Attempt int
+	Until   time.Time
+	Reason  string
+}
+
+type retryNotifierKey struct{}
+
+// WithRetryNotifier returns a context whose requests report waits before retries to notify.
+func WithRetryNotifier(ctx context.Context, notify func(RetryEvent)) context.Context {
+	return context.WithValue(ctx, retryNotifierKey{}, notify)
+}
+
+// wait sleeps until the retry event deadline, reporting it to the notifier of ctx.
+func wait(ctx context.Context, event RetryEvent) error {
+	log.Printf("Retrying after attempt %d failed (%s) at %s", event.Attempt, event.Reason, event.Until.Format("15:04:05"))
+	if notify, ok := ctx.Value(retryNotifierKey{}).(func(RetryEvent)); ok {
+		notify(event)
+	}
+	timer := time.NewTimer(time.Until(event.Until))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// backoff returns the delay before the attempt following attempt, growing exponentially with jitter.
+func (policy RetryPolicy) backoff(attempt int) time.Duration {
+	delay := policy.MinBackoff << (attempt - 1)
+	if delay > policy.MaxBackoff || delay <= 0 {
+		delay = policy.MaxBackoff
+	}
+	return policy.MinBackoff/2 + time.Duration(rand.Int63n(int64(delay)+1))
+}
+
+// newHTTPClient returns a copy of client going through mirrors and retrying requests according to policy.
+func newHTTPClient(client *http.Client, policy RetryPolicy, mirrors MirrorsConfig) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if transport, ok := base.(*http.Transport); ok && policy.Timeout > 0 {
+		transport = transport.Clone()
+		transport.ResponseHeaderTimeout = policy.Timeout
+		base = transport
+	}
+	if len(mirrors.Mirrors) > 0 {
+		base = mirrorTransport{base: base, config: mirrors}
+	}
+	return &http.Client{
+		Transport:     retryTransport{base: base, policy: policy},
+		CheckRedirect: client.CheckRedirect,
+		Jar:           client.Jar,
+		Timeout:       client.Timeout,
+	}
+}
+
+// retryTransport retries requests failing with network errors, server errors or rate limits, unless they
+// are retried by [GPM.retry].
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+}
+
+// noRetryKey marks the contexts of requests that [retryTransport] must not retry.
+type noRetryKey struct{}
+
+func (t retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if ctx.Value(noRetryKey{}) != nil {
+		return t.base.RoundTrip(req)
+	}
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		res, err := t.base.RoundTrip(req)
+		if ctx.Err() != nil || attempt >= t.policy.Attempts {
+			return res, err
+		}
+		reason, until, ok := t.policy.retryReason(res, err)
+		if !ok {
+			return res, err
+		}
+		if res != nil {
+			res.Body.Close()
+		}
+		if until.IsZero() {
+			until = time.Now().Add(t.policy.backoff(attempt))
+		}
+		if err := wait(ctx, RetryEvent{Attempt: attempt, Until: until, Reason: reason}); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryReason tells why a request that got res or err can be retried. until is when the rate limit that
+// failed the request resets, or zero when the backoff of policy applies.
+func (policy RetryPolicy) retryReason(res *http.Response, err error) (reason string, until time.Time, ok bool) {
+	switch {
+	case err != nil:
+		return err.Error(), time.Time{}, isTransientError(err)
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusForbidden:
+		until, limited := rateLimitReset(res)
+		switch {
+		case limited && time.Until(until) > policy.MaxWait:
+			return "", time.Time{}, false
+		case limited:
+			return "rate limit exceeded", until, true
+		case res.StatusCode == http.StatusTooManyRequests:
+			return res.Status, time.Time{}, true
+		}
+	case res.StatusCode >= http.StatusInternalServerError:
+		return res.Status, time.Time{}, true
+	}
+	return "", time.Time{}, false
+}
+
+// isTransientError tells whether a request that failed with err may succeed when retried, like on timeouts
+// or reset connections, unlike on invalid certificates or unsupported URLs.
+func isTransientError(err error) bool {
+	var netErr net.Error
+	var opErr *net.OpError
+	switch {
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF),
+		errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.EPIPE):
+		return true
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	}
+	return IsNetworkError(err) && errors.As(err, &opErr)
+}
+
+// rateLimitReset returns when a rate limited response allows to retry, from the Retry-After header
+// or the Github X-RateLimit-Reset header.
+func rateLimitReset(res *http.Response) (time.Time, bool) {
+	if retryAfter := res.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return time.Now().Add(time.Duration(seconds) * time.Second), true
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return date, true
+		}
+	}
+	if res.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(res.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			// Leave a second of margin for clock skew.
+			return time.Unix(reset+1, 0), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// retryableError marks errors of operations that can be attempted again, like interrupted downloads.
+type retryableError struct {
+	error
+	// until is when a rate limit allows to attempt again, zero to back off.
+	until time.Time
+}
+
+func (err retryableError) Unwrap() error { return err.error }
+
+// retry calls fn until it succeeds, fails with an error that is not a [retryableError],
+// or the attempts of the retry policy are exhausted. Requests made with the context passed to fn are
+// not retried by the HTTP client, so that attempts are only counted here.
+func (gpm GPM) retry(ctx context.Context, fn func(ctx context.Context) error) error {
+	fnCtx := context.WithValue(ctx, noRetryKey{}, true)
+	for attempt := 1; ; attempt++ {
+		err := fn(fnCtx)
+		var retryable retryableError
+		if err == nil || !errors.As(err, &retryable) || ctx.Err() != nil || attempt >= gpm.retryPolicy.Attempts {
+			return err
+		}
+		event := RetryEvent{
+			Attempt: attempt,
+			Until:   retryable.until,
+			Reason:  retryable.Error(),
+		}
+		if event.Until.IsZero() {
+			event.Until = time.Now().Add(gpm.retryPolicy.backoff(attempt))
+		}
+		if err := wait(ctx, event); err != nil {
+			return fmt.Errorf("%w (after %s)", err, retryable.Error())
+		}
+	}
+}
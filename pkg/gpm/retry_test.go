@@ -0,0 +1,152 @@
+package gpm
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		header       http.Header
+		wantStatus   int
+		wantRequests int
+		wantEvents   int
+	}{
+		{"Server errors", []int{502, 503, 200}, nil, 200, 3, 2},
+		{"Attempts exhausted", []int{502, 502, 502, 502}, nil, 502, 3, 2},
+		{"Forbidden", []int{403, 200}, nil, 403, 1, 0},
+		{"Rate limited", []int{403, 200}, http.Header{"Retry-After": {"0"}}, 200, 2, 1},
+		{"Rate limit reset too late", []int{403, 200}, http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"4102444800"}}, 403, 1, 0},
+		{"Not found", []int{404, 200}, nil, 404, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for key, values := range tt.header {
+					w.Header()[key] = values
+				}
+				w.WriteHeader(tt.statuses[requests])
+				requests++
+			}))
+			defer server.Close()
+
+			gpm := NewGPM(WithHTTPClient(server.Client()), WithRetryPolicy(RetryPolicy{
+				Attempts:   3,
+				MinBackoff: time.Millisecond,
+				MaxBackoff: time.Millisecond,
+				MaxWait:    time.Minute,
+			}))
+			events := 0
+			ctx := WithRetryNotifier(context.Background(), func(RetryEvent) { events++ })
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := gpm.HTTPClient().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if res.StatusCode != tt.wantStatus || requests != tt.wantRequests || events != tt.wantEvents {
+				t.Errorf("got status %d after %d requests and %d events, want %d after %d requests and %d events",
+					res.StatusCode, requests, events, tt.wantStatus, tt.wantRequests, tt.wantEvents)
+			}
+		})
+	}
+}
+
+// errorTransport counts the requests it fails with err.
+type errorTransport struct {
+	err      error
+	requests *int
+}
+
+func (t errorTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	*t.requests++
+	return nil, t.err
+}
+
+func TestRetryTransport_Errors(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantRequests int
+	}{
+		{"Connection reset", &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, 3},
+		{"Connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, 3},
+		{"Unexpected EOF", io.ErrUnexpectedEOF, 3},
+		{"Timeout", &net.DNSError{Err: "i/o timeout", IsTimeout: true}, 3},
+		{"Unknown authority", x509.UnknownAuthorityError{}, 1},
+		{"Unsupported protocol scheme", errors.New(`unsupported protocol scheme "ftp"`), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			gpm := NewGPM(WithHTTPClient(&http.Client{Transport: errorTransport{tt.err, &requests}}), WithRetryPolicy(RetryPolicy{
+				Attempts:   3,
+				MinBackoff: time.Millisecond,
+				MaxBackoff: time.Millisecond,
+			}))
+			res, err := gpm.HTTPClient().Get("https://example.com")
+			if err == nil {
+				res.Body.Close()
+				t.Fatal("Get() succeeded, want an error")
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("Get() sent %d requests, want %d", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestRetry_Download(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantErr      bool
+		wantRequests int
+	}{
+		{"Server errors", []int{503, 502, 200}, false, 3},
+		{"Attempts exhausted", []int{503, 503, 503, 503, 503, 503, 503, 503, 503}, true, 3},
+		{"Not found", []int{404, 200}, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statuses[requests])
+				requests++
+			}))
+			defer server.Close()
+
+			gpm := NewGPM(WithHTTPClient(server.Client()), WithRetryPolicy(RetryPolicy{
+				Attempts:   3,
+				MinBackoff: time.Millisecond,
+				MaxBackoff: time.Millisecond,
+			}))
+			dst := filepath.Join(t.TempDir(), "asset")
+			err := gpm.retry(context.Background(), func(ctx context.Context) error {
+				return gpm.Download(ctx, server.URL, dst, nil)
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("retry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("retry() sent %d requests, want %d", requests, tt.wantRequests)
+			}
+		})
+	}
+}
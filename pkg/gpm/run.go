@@ -0,0 +1,30 @@
+package gpm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// RunExecutable fetches dep into the store if needed, without linking it, and returns it resolved with
+// the name and path of its executable named name. An empty name selects the executable named after the
+// repository, or the only executable of dep. The entry of dep is locked until release is called, or the
+// process execs, so that [GPM.GC] does not remove it meanwhile.
+func (gpm GPM) RunExecutable(ctx context.Context, dep Dependency, name string) (_ Dependency, _ string, _ string, release func(), err error) {
+	dep, executables, unlock, err := gpm.fetchDependency(ctx, dep, nil)
+	if err != nil {
+		return dep, "", "", nil, err
+	}
+	if name == "" {
+		name = dep.Repo
+		if _, ok := executables[name]; !ok && len(executables) == 1 {
+			name = sortedKeys(executables)[0]
+		}
+	}
+	filePath, ok := executables[name]
+	if !ok {
+		unlock()
+		return dep, "", "", nil, fmt.Errorf("no executable named %q in %q, choose one of %s", name, dep, strings.Join(sortedKeys(executables), ", "))
+	}
+	return dep, name, filePath, unlock, nil
+}
@@ -0,0 +1,65 @@
+package gpm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunExecutable(t *testing.T) {
+	tmp := t.TempDir()
+	storePath := filepath.Join(tmp, "store")
+	binPath := filepath.Join(tmp, "bin")
+	for _, name := range []string{"tool", "multi"} {
+		dir := filepath.Join(storePath, "github.com", "owner", name, "v1.0.0", name+"_linux_amd64")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range []string{name, name + "-extra"} {
+			if err := os.WriteFile(filepath.Join(dir, file), []byte("#!/bin/sh\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	gpm := NewGPM(WithStorePath(storePath), WithBinPath(binPath), WithConfigPath(filepath.Join(tmp, "config")), WithOffline(true))
+	multi := &Package{Repo: "owner/multi", Bin: map[string]string{"multi": "multi", "extra": "multi-extra"}}
+
+	tests := []struct {
+		name     string
+		dep      Dependency
+		exec     string
+		wantName string
+		wantFile string
+		wantErr  bool
+	}{
+		{"Only executable", Dependency{Owner: "owner", Repo: "tool", ReleaseTag: "v1.0.0"}, "", "tool", "tool", false},
+		{"Named after repository", Dependency{Owner: "owner", Repo: "multi", ReleaseTag: "1.0.0", Package: multi}, "", "multi", "multi", false},
+		{"Named", Dependency{Owner: "owner", Repo: "multi", Package: multi}, "extra", "extra", "multi-extra", false},
+		{"Unknown name", Dependency{Owner: "owner", Repo: "tool"}, "extra", "", "", true},
+		{"Not cached", Dependency{Owner: "owner", Repo: "tool", ReleaseTag: "v2.0.0"}, "", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, name, filePath, release, err := gpm.RunExecutable(context.Background(), tt.dep, tt.exec)
+			if err == nil {
+				release()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RunExecutable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if name != tt.wantName || filepath.Base(filePath) != tt.wantFile {
+				t.Errorf("RunExecutable() = %q, %q, want %q, %q", name, filePath, tt.wantName, tt.wantFile)
+			}
+			if fileInfo, err := os.Stat(filePath); err != nil || fileInfo.Mode()&0100 == 0 {
+				t.Errorf("RunExecutable() returned %q, which is not executable", filePath)
+			}
+		})
+	}
+	if _, err := os.Stat(binPath); !os.IsNotExist(err) {
+		t.Errorf("RunExecutable() created bin dir %q", binPath)
+	}
+}
@@ -0,0 +1,176 @@
+package gpm
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Scheduler bounds the number of concurrent operations of installs, overall and per host. Waiting
+// operations are started by increasing size, so that API calls and small assets go first.
+type Scheduler struct {
+	jobs    int
+	perHost int
+
+	mtx     sync.Mutex
+	running int
+	hosts   map[string]int
+	queue   []*ticket
+	seq     int
+}
+
+type ticket struct {
+	host  string
+	size  int64
+	seq   int
+	ready chan struct{}
+}
+
+// NewScheduler returns a [Scheduler] running at most jobs operations at once, and at most perHost
+// operations against the same host. A perHost of 0 only limits the total.
+func NewScheduler(jobs, perHost int) *Scheduler {
+	if jobs < 1 {
+		jobs = 1
+	}
+	return &Scheduler{
+		jobs:    jobs,
+		perHost: perHost,
+		hosts:   map[string]int{},
+	}
+}
+
+// WithScheduler bounds concurrent installs with scheduler. Without scheduler, installs are not bounded.
+func WithScheduler(scheduler *Scheduler) GPMOption {
+	return func(gpm *GPM) {
+		gpm.scheduler = scheduler
+	}
+}
+
+// Acquire waits for a slot to run an operation against host, involving size bytes.
+// The returned function must be called to free the slot.
+func (s *Scheduler) Acquire(ctx context.Context, host string, size int64) (func(), error) {
+	s.mtx.Lock()
+	t := &ticket{host: host, size: size, seq: s.seq, ready: make(chan struct{})}
+	s.seq++
+	s.queue = append(s.queue, t)
+	s.dispatch()
+	s.mtx.Unlock()
+
+	select {
+	case <-t.ready:
+		var once sync.Once
+		return func() { once.Do(func() { s.release(host) }) }, nil
+	case <-ctx.Done():
+		s.mtx.Lock()
+		defer s.mtx.Unlock()
+		select {
+		case <-t.ready:
+			s.running--
+			s.hosts[host]--
+			s.dispatch()
+		default:
+			for i := range s.queue {
+				if s.queue[i] == t {
+					s.queue = append(s.queue[:i], s.queue[i+1:]...)
+					break
+				}
+			}
+		}
+		return nil, ctx.Err()
+	}
+}
+
+func (s *Scheduler) release(host string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.running--
+	s.hosts[host]--
+	s.dispatch()
+}
+
+// dispatch starts waiting operations while slots are available. s.mtx must be held.
+func (s *Scheduler) dispatch() {
+	sort.SliceStable(s.queue, func(i, j int) bool {
+		if s.queue[i].size != s.queue[j].size {
+			return s.queue[i].size < s.queue[j].size
+		}
+		return s.queue[i].seq < s.queue[j].seq
+	})
+	for i := 0; i < len(s.queue) && s.running < s.jobs; {
+		t := s.queue[i]
+		if s.perHost > 0 && s.hosts[t.host] >= s.perHost {
+			i++
+			continue
+		}
+		s.running++
+		s.hosts[t.host]++
+		close(t.ready)
+		s.queue = append(s.queue[:i], s.queue[i+1:]...)
+	}
+}
+
+// schedulerHost returns the host serving rawURL, through the first mirror matching it if any, so that
+// requests are bounded per host they are actually sent to.
+func (gpm GPM) schedulerHost(rawURL string) string {
+	for _, mirror := range gpm.mirrors.Mirrors {
+		if strings.HasPrefix(rawURL, mirror.Prefix) {
+			rawURL = mirror.URL + strings.TrimPrefix(rawURL, mirror.Prefix)
+			break
+		}
+	}
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return rawURL
+}
+
+// acquire waits for a slot of the scheduler of gpm, if any.
+func (gpm GPM) acquire(ctx context.Context, host string, size int64) (func(), error) {
+	if gpm.scheduler == nil {
+		return func() {}, nil
+	}
+	notifyState(ctx, StateQueued)
+	return gpm.scheduler.Acquire(ctx, host, size)
+}
+
+// InstallState is the stage an install is at.
+type InstallState int
+
+const (
+	StateQueued InstallState = iota
+	StateResolving
+	StateDownloading
+	StateInstalling
+	StateTesting
+)
+
+func (state InstallState) String() string {
+	switch state {
+	case StateQueued:
+		return "queued"
+	case StateResolving:
+		return "resolving"
+	case StateDownloading:
+		return "downloading"
+	case StateInstalling:
+		return "installing"
+	case StateTesting:
+		return "testing"
+	}
+	return "unknown"
+}
+
+type stateNotifierKey struct{}
+
+// WithStateNotifier returns a context whose installs report their stage to notify.
+func WithStateNotifier(ctx context.Context, notify func(InstallState)) context.Context {
+	return context.WithValue(ctx, stateNotifierKey{}, notify)
+}
+
+func notifyState(ctx context.Context, state InstallState) {
+	if notify, ok := ctx.Value(stateNotifierKey{}).(func(InstallState)); ok {
+		notify(state)
+	}
+}
@@ -0,0 +1,74 @@
+package gpm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScheduler_SmallFirst(t *testing.T) {
+	s := NewScheduler(1, 0)
+	ctx := context.Background()
+	release, _ := s.Acquire(ctx, "github.com", 0)
+
+	started := make(chan string, 2)
+	for _, op := range []struct {
+		name string
+		size int64
+	}{{"big", 1000}, {"small", 10}} {
+		go func(name string, size int64) {
+			release, err := s.Acquire(ctx, "github.com", size)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			started <- name
+			release()
+		}(op.name, op.size)
+		// Let the operation queue.
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	release()
+	if order := []string{<-started, <-started}; !reflect.DeepEqual(order, []string{"small", "big"}) {
+		t.Errorf("operations started in order %v, want [small big]", order)
+	}
+}
+
+func TestScheduler_PerHost(t *testing.T) {
+	s := NewScheduler(2, 1)
+	ctx := context.Background()
+	release, _ := s.Acquire(ctx, "github.com", 0)
+	defer release()
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.Acquire(timeout, "github.com", 0); err == nil {
+		t.Error("Acquire() exceeded the per host limit")
+	}
+	other, err := s.Acquire(ctx, "api.github.com", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other()
+}
+
+func TestSchedulerHost(t *testing.T) {
+	gpm := NewGPM(WithMirrors(MirrorsConfig{Mirrors: []Mirror{
+		{Name: "api", Prefix: "https://api.github.com/", URL: "https://proxy.example.com/github-api/"},
+	}}))
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{gpm.GithubClient().BaseURL.String(), "proxy.example.com"},
+		{"https://github.com/owner/repo/releases/download/v1/asset", "github.com"},
+		{"https://objects.githubusercontent.com/asset", "objects.githubusercontent.com"},
+	}
+	for _, tt := range tests {
+		if got := gpm.schedulerHost(tt.url); got != tt.want {
+			t.Errorf("schedulerHost(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package gpm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-github/v47/github"
+	"github.com/hashicorp/go-getter/v2"
+)
+
+// Repository gpm is released from.
+const (
+	SelfOwner = "ctison"
+	SelfRepo  = "gpm"
+)
+
+// SelfAssetName returns the name of the release asset of gpm version for platform, as produced by
+// .goreleaser.yml with binary archives.
+func SelfAssetName(version string, platform Platform) string {
+	arch := platform.Arch
+	if arch == "arm" {
+		// Default GOARM of goreleaser.
+		arch = "armv6"
+	}
+	return fmt.Sprintf("%s_%s_%s_%s", SelfRepo, strings.TrimPrefix(version, "v"), platform.OS, arch)
+}
+
+// selfChecksumsName returns the name of the checksums asset of gpm version, as produced by goreleaser.
+func selfChecksumsName(version string) string {
+	return fmt.Sprintf("%s_%s_checksums.txt", SelfRepo, strings.TrimPrefix(version, "v"))
+}
+
+// SelfUpdate is the outcome of [GPM.SelfUpdate].
+type SelfUpdate struct {
+	CurrentVersion string `json:"current_version"`
+	LatestVersion  string `json:"latest_version"`
+	// Executable is the path of the running gpm, with symlinks resolved.
+	Executable string `json:"executable"`
+	// Available reports whether the latest release is newer than the running gpm.
+	Available bool `json:"available"`
+	Updated   bool `json:"updated"`
+	// Links are the links relinked to the new version when gpm is installed in the store.
+	Links []string `json:"links,omitempty"`
+}
+
+// SelfUpdate replaces the running gpm, of version currentVersion, by the latest release of gpm if it is
+// newer, or unconditionally with force. With check, the update is only looked up. The release asset is
+// verified against the checksums of the release. When gpm runs from the store, the new version is
+// installed into the store and the links to the running gpm are pointed to it. Otherwise, the executable
+// is replaced in place by a rename.
+func (gpm GPM) SelfUpdate(ctx context.Context, currentVersion string, check, force bool, progressTracker getter.ProgressTracker) (*SelfUpdate, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get path of gpm: %w", err)
+	}
+	if executable, err = filepath.EvalSymlinks(executable); err != nil {
+		return nil, fmt.Errorf("failed to resolve path of gpm: %w", err)
+	}
+	return gpm.selfUpdate(ctx, executable, currentVersion, check, force, progressTracker)
+}
+
+// selfUpdate updates the gpm at executable. See [GPM.SelfUpdate].
+func (gpm GPM) selfUpdate(ctx context.Context, executable, currentVersion string, check, force bool, progressTracker getter.ProgressTracker) (*SelfUpdate, error) {
+	release, err := gpm.GetRelease(ctx, SelfOwner, SelfRepo, "latest")
+	if err != nil {
+		return nil, err
+	}
+	update := &SelfUpdate{
+		CurrentVersion: currentVersion,
+		LatestVersion:  release.GetTagName(),
+		Executable:     executable,
+	}
+	update.Available = compareVersions(update.LatestVersion, currentVersion) > 0
+	if check || (!update.Available && !force) {
+		return update, nil
+	}
+
+	assetName := SelfAssetName(update.LatestVersion, CurrentPlatform())
+	asset, checksumsAsset := findReleaseAsset(release, assetName), findReleaseAsset(release, selfChecksumsName(update.LatestVersion))
+	if asset == nil {
+		return nil, fmt.Errorf("no asset named %q in release %s/%s@%s", assetName, SelfOwner, SelfRepo, update.LatestVersion)
+	}
+	if checksumsAsset == nil {
+		return nil, fmt.Errorf("no checksums to verify %q in release %s/%s@%s", assetName, SelfOwner, SelfRepo, update.LatestVersion)
+	}
+
+	if _, _, ok := gpm.StoreDependency(executable); ok {
+		links, err := gpm.selfUpdateStore(ctx, update, assetName, checksumsAsset.GetName(), progressTracker)
+		if err != nil {
+			return nil, err
+		}
+		update.Links = links
+		update.Updated = true
+		return update, nil
+	}
+
+	stagingPath, err := gpm.GetStagingPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gpm staging path: %w", err)
+	}
+	staged := filepath.Join(stagingPath, "github.com", SelfOwner, SelfRepo, update.LatestVersion, assetName)
+	defer func() {
+		if err := RemoveDownload(staged); err != nil {
+			log.Printf("Failed to remove %q: %s", staged, err.Error())
+		}
+		removeEmptyParents(filepath.Dir(staged), stagingPath)
+	}()
+	err = gpm.retry(ctx, func(ctx context.Context) error {
+		return gpm.Download(ctx, asset.GetBrowserDownloadURL(), staged, progressTracker)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %q: %w", assetName, err)
+	}
+	checksums, err := gpm.Fetch(ctx, checksumsAsset.GetBrowserDownloadURL())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checksums of %q: %w", assetName, err)
+	}
+	if err := VerifyChecksum(staged, assetName, checksums); err != nil {
+		return nil, err
+	}
+	if err := replaceExecutable(staged, executable); err != nil {
+		return nil, err
+	}
+	log.Printf("Replaced %q by %s", executable, assetName)
+	update.Updated = true
+	return update, nil
+}
+
+// selfUpdateStore installs the latest gpm into the store, and points the links to the running gpm to it.
+func (gpm GPM) selfUpdateStore(ctx context.Context, update *SelfUpdate, assetName, checksumsName string, progressTracker getter.ProgressTracker) ([]string, error) {
+	dep := Dependency{
+		Owner:      SelfOwner,
+		Repo:       SelfRepo,
+		ReleaseTag: update.LatestVersion,
+		AssetName:  assetName,
+		Package:    &Package{Repo: SelfOwner + "/" + SelfRepo, Checksum: checksumsName},
+	}
+	dep, executables, unlock, err := gpm.fetchDependency(ctx, dep, progressTracker)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	filePath, ok := executables[SelfRepo]
+	if !ok {
+		return nil, fmt.Errorf("no executable found in %s", dep)
+	}
+	storeLinks, err := gpm.storeLinks()
+	if err != nil {
+		return nil, err
+	}
+	_, entryPath, _ := gpm.StoreDependency(update.Executable)
+	links := map[string]string{}
+	for _, symLinkPath := range storeLinks[entryPath] {
+		if target, err := readLink(symLinkPath); err == nil && target == update.Executable {
+			links[symLinkPath] = filePath
+		}
+	}
+	if len(links) == 0 {
+		// gpm was run from the store directly, link it into the bin dir like gpm install does.
+		replaced, err := gpm.linkExecutables(ctx, executables)
+		if err != nil {
+			return nil, err
+		}
+		return sortedKeys(replaced), nil
+	}
+	if _, err := gpm.replaceLinks(ctx, links); err != nil {
+		return nil, err
+	}
+	return sortedKeys(links), nil
+}
+
+// findReleaseAsset returns the asset of release named name, or nil.
+func findReleaseAsset(release *github.RepositoryRelease, name string) *github.ReleaseAsset {
+	for _, asset := range release.Assets {
+		if asset.GetName() == name {
+			return asset
+		}
+	}
+	return nil
+}
+
+// replaceExecutable copies src next to executable then renames it over executable, so that the running
+// executable is never seen partially written.
+func replaceExecutable(src, executable string) error {
+	tmpPath := filepath.Join(filepath.Dir(executable), fmt.Sprintf(".%s.tmp-%d", filepath.Base(executable), rand.Int63()))
+	if err := copyFile(src, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		if errors.Is(err, os.ErrPermission) {
+			return fmt.Errorf("no permission to replace %q, run gpm self-update as its owner: %w", executable, err)
+		}
+		return fmt.Errorf("failed to copy new gpm next to %q: %w", executable, err)
+	}
+	if err := os.Chmod(tmpPath, 0755); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to chmod 755 %q: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, executable); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace %q: %w", executable, err)
+	}
+	return nil
+}
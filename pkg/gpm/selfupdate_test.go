@@ -0,0 +1,130 @@
+package gpm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSelfUpdate(t *testing.T) {
+	assetName := SelfAssetName("v1.1.0", CurrentPlatform())
+	content := "#!/bin/sh\necho gpm 1.1.0\n"
+	checksums := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/ctison/gpm/releases/latest", "/repos/ctison/gpm/releases/tags/v1.1.0":
+			json.NewEncoder(w).Encode(map[string]any{
+				"tag_name": "v1.1.0",
+				"assets": []map[string]string{
+					{"name": assetName, "browser_download_url": "https://github.com/download/" + assetName},
+					{"name": "gpm_1.1.0_checksums.txt", "browser_download_url": "https://github.com/download/checksums.txt"},
+				},
+			})
+		case "/download/" + assetName:
+			w.Write([]byte(content))
+		case "/download/checksums.txt":
+			w.Write([]byte(checksums))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	sum := sha256.Sum256([]byte(content))
+	validChecksums := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), assetName)
+
+	tmp := t.TempDir()
+	storePath := filepath.Join(tmp, "store")
+	binPath := filepath.Join(tmp, "bin")
+	gpm := NewGPM(
+		WithStorePath(storePath),
+		WithBinPath(binPath),
+		WithConfigPath(filepath.Join(tmp, "config")),
+		WithHTTPClient(&http.Client{Transport: serverTransport{server}}),
+		WithHTTPCache(false),
+		WithRetryPolicy(RetryPolicy{Attempts: 1}),
+	)
+	ctx := context.Background()
+
+	executable := filepath.Join(tmp, "gpm")
+	writeOld := func() {
+		if err := os.WriteFile(executable, []byte("old"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		version     string
+		check       bool
+		checksums   string
+		wantUpdated bool
+		wantErr     bool
+	}{
+		{"Up to date", "v1.1.0", false, validChecksums, false, false},
+		{"Check only", "v1.0.0", true, validChecksums, false, false},
+		{"Bad checksum", "v1.0.0", false, strings.Repeat("0", 64) + "  " + assetName + "\n", false, true},
+		{"Updated", "v1.0.0", false, validChecksums, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeOld()
+			checksums = tt.checksums
+			update, err := gpm.selfUpdate(ctx, executable, tt.version, tt.check, false, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selfUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && update.Updated != tt.wantUpdated {
+				t.Errorf("selfUpdate() updated = %v, want %v", update.Updated, tt.wantUpdated)
+			}
+			want := "old"
+			if tt.wantUpdated {
+				want = content
+			}
+			if data, err := os.ReadFile(executable); err != nil || string(data) != want {
+				t.Errorf("executable holds %q, %v, want %q", data, err, want)
+			}
+		})
+	}
+
+	// gpm installed with gpm is updated in the store and relinked.
+	oldEntry := filepath.Join(storePath, "github.com", "ctison", "gpm", "v1.0.0", SelfAssetName("v1.0.0", CurrentPlatform()))
+	if err := os.MkdirAll(oldEntry, 0755); err != nil {
+		t.Fatal(err)
+	}
+	oldExecutable := filepath.Join(oldEntry, "gpm")
+	if err := os.WriteFile(oldExecutable, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	otherBinPath := filepath.Join(tmp, "other-bin")
+	if err := os.MkdirAll(otherBinPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	linkPath := filepath.Join(otherBinPath, "gpm")
+	if err := os.Symlink(oldExecutable, linkPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := gpm.recordBinPath(otherBinPath); err != nil {
+		t.Fatal(err)
+	}
+	update, err := gpm.selfUpdate(ctx, oldExecutable, "v1.0.0", false, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(update.Links) != 1 || update.Links[0] != linkPath {
+		t.Errorf("selfUpdate() relinked %v, want [%s]", update.Links, linkPath)
+	}
+	if data, err := os.ReadFile(linkPath); err != nil || string(data) != content {
+		t.Errorf("%q runs %q, %v, want %q", linkPath, data, err, content)
+	}
+	if data, err := os.ReadFile(oldExecutable); err != nil || string(data) != "old" {
+		t.Errorf("selfUpdate() modified the old store entry: %q, %v", data, err)
+	}
+}
@@ -0,0 +1,268 @@
+package gpm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v47/github"
+)
+
+// SemVer is a semantic version parsed from a release tag by [ParseVersion].
+type SemVer struct {
+	Major, Minor, Patch int
+	Prerelease          string
+}
+
+func (v SemVer) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// regexpVersion matches versions in release tags, with an optional "v" or "release-" prefix. The minor
+// and patch numbers are optional, and build metadata is ignored.
+var regexpVersion = regexp.MustCompile(`^(?:release[-_]?)?[vV]?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// ParseVersion parses the release tag as a semantic version, like v1.2.3, 1.2.3 or release-1.2.3.
+func ParseVersion(tag string) (SemVer, bool) {
+	v, _, ok := parseVersion(tag)
+	return v, ok
+}
+
+// parseVersion is [ParseVersion] also returning the number of version components in tag.
+func parseVersion(tag string) (SemVer, int, bool) {
+	match := regexpVersion.FindStringSubmatch(tag)
+	if match == nil {
+		return SemVer{}, 0, false
+	}
+	var v SemVer
+	components := 0
+	for i, n := range []*int{&v.Major, &v.Minor, &v.Patch} {
+		if match[i+1] == "" {
+			break
+		}
+		var err error
+		if *n, err = strconv.Atoi(match[i+1]); err != nil {
+			return SemVer{}, 0, false
+		}
+		components++
+	}
+	v.Prerelease = match[4]
+	return v, components, true
+}
+
+// Compare returns -1, 0 or 1 whether v is lower, equal or greater than other. Prereleases are lower than
+// their release, and compared by their dot separated identifiers.
+func (v SemVer) Compare(other SemVer) int {
+	for _, d := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	switch {
+	case v.Prerelease == other.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case other.Prerelease == "":
+		return -1
+	}
+	a, b := strings.Split(v.Prerelease, "."), strings.Split(other.Prerelease, ".")
+	for i := 0; i < len(a) && i < len(b); i++ {
+		na, errA := strconv.Atoi(a[i])
+		nb, errB := strconv.Atoi(b[i])
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				return sign(na - nb)
+			}
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(a) - len(b))
+}
+
+// compareVersions compares the release tags a and b as versions, see [SemVer.Compare]. Tags that are not
+// versions are only equal to themselves, and lower than any version.
+func compareVersions(a, b string) int {
+	va, okA := ParseVersion(a)
+	vb, okB := ParseVersion(b)
+	switch {
+	case !okA && !okB:
+		return strings.Compare(a, b)
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+	return va.Compare(vb)
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// Constraint is a range of versions parsed by [ParseConstraint].
+type Constraint struct {
+	raw         string
+	comparators []comparator
+}
+
+type comparator struct {
+	op      string
+	version SemVer
+}
+
+// regexpConstraintPrefix matches the start of tags that are constraints rather than release tags.
+var regexpConstraintPrefix = regexp.MustCompile(`^(\^|~|[<>]=?|=)`)
+
+// IsConstraint reports whether tag is a version constraint rather than a release tag.
+func IsConstraint(tag string) bool {
+	return regexpConstraintPrefix.MatchString(strings.TrimSpace(tag))
+}
+
+// ParseConstraint parses a version constraint made of comparators separated by spaces or commas, which
+// must all match:
+//   - ^1.4 allows changes that do not modify the left-most non-zero component (>=1.4.0 <2.0.0),
+//   - ~2.3.0 allows patch changes (>=2.3.0 <2.4.0), or minor changes when only a major is given,
+//   - >=, >, <=, < and = compare with a version.
+//
+// Prereleases only match when a comparator holds a prerelease.
+func ParseConstraint(s string) (Constraint, error) {
+	constraint := Constraint{raw: s}
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' })
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		op := regexpConstraintPrefix.FindString(field)
+		// Allow a space between the operator and the version, like ">= 1.0".
+		if op == field && i+1 < len(fields) {
+			i++
+			field += fields[i]
+		}
+		if op == "" {
+			op = "="
+		}
+		v, components, ok := parseVersion(strings.TrimPrefix(field, op))
+		if !ok {
+			return Constraint{}, fmt.Errorf("invalid version constraint %q: %q is not a version", s, field)
+		}
+		switch op {
+		case "^":
+			upper := SemVer{Major: v.Major + 1}
+			switch {
+			case v.Major == 0 && components == 1:
+				upper = SemVer{Major: 1}
+			case v.Major == 0 && (v.Minor > 0 || components == 2):
+				upper = SemVer{Minor: v.Minor + 1}
+			case v.Major == 0:
+				upper = SemVer{Patch: v.Patch + 1}
+			}
+			constraint.comparators = append(constraint.comparators, comparator{">=", v}, comparator{"<", upper})
+		case "~":
+			upper := SemVer{Major: v.Major, Minor: v.Minor + 1}
+			if components == 1 {
+				upper = SemVer{Major: v.Major + 1}
+			}
+			constraint.comparators = append(constraint.comparators, comparator{">=", v}, comparator{"<", upper})
+		default:
+			constraint.comparators = append(constraint.comparators, comparator{op, v})
+		}
+	}
+	if len(constraint.comparators) == 0 {
+		return Constraint{}, fmt.Errorf("empty version constraint")
+	}
+	return constraint, nil
+}
+
+func (c Constraint) String() string { return c.raw }
+
+// Match reports whether v satisfies all the comparators of c.
+func (c Constraint) Match(v SemVer) bool {
+	if v.Prerelease != "" {
+		allowed := false
+		for _, comparator := range c.comparators {
+			allowed = allowed || comparator.version.Prerelease != ""
+		}
+		if !allowed {
+			return false
+		}
+	}
+	for _, comparator := range c.comparators {
+		cmp := v.Compare(comparator.version)
+		var ok bool
+		switch comparator.op {
+		case "=":
+			ok = cmp == 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchTag returns the highest of tags that is a version satisfying c. Tags that are not versions are
+// ignored.
+func (c Constraint) MatchTag(tags []string) (string, bool) {
+	best, bestVersion := "", SemVer{}
+	for _, tag := range tags {
+		if v, ok := ParseVersion(tag); ok && c.Match(v) && (best == "" || v.Compare(bestVersion) > 0) {
+			best, bestVersion = tag, v
+		}
+	}
+	return best, best != ""
+}
+
+// getConstraintRelease returns the release whose tag is the highest version satisfying the constraint.
+// Drafts and tags that are not versions are ignored.
+func (gpm GPM) getConstraintRelease(ctx context.Context, owner, repo, constraint string) (*github.RepositoryRelease, error) {
+	c, err := ParseConstraint(constraint)
+	if err != nil {
+		return nil, err
+	}
+	releases := map[string]*github.RepositoryRelease{}
+	var tags []string
+	err = gpm.forEachRelease(ctx, owner, repo, func(release *github.RepositoryRelease) bool {
+		if !release.GetDraft() {
+			releases[release.GetTagName()] = release
+			tags = append(tags, release.GetTagName())
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	tag, ok := c.MatchTag(tags)
+	if !ok {
+		return nil, fmt.Errorf("no release of %s/%s matches %q among %d releases", owner, repo, constraint, len(tags))
+	}
+	log.Printf("Resolved %s/%s@%s to %s", owner, repo, constraint, tag)
+	return releases[tag], nil
+}
@@ -0,0 +1,130 @@
+package gpm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.3", "1.2.3", 0},
+		{"release-1.2.3", "v1.2.3", 0},
+		{"v1.10.0", "v1.9.0", 1},
+		{"v1.2", "v1.2.1", -1},
+		{"v2.0.0-rc.1", "v2.0.0", -1},
+		{"v2.0.0-rc.10", "v2.0.0-rc.9", 1},
+		{"v2.0.0-beta", "v2.0.0-alpha.1", 1},
+		{"v0.1.0", "snapshot-abc", 1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestConstraint(t *testing.T) {
+	tags := []string{"v2.0.0", "v1.5.2", "1.4.9", "release-1.4.0", "v1.3.0", "v1.5.3-rc.1", "v0.9.5", "v0.4.2", "v0.4.1", "nightly", "v1.0"}
+	tests := []struct {
+		constraint string
+		want       string
+		wantErr    bool
+	}{
+		{"^1.4", "v1.5.2", false},
+		{"~1.4.0", "1.4.9", false},
+		{"~1", "v1.5.2", false},
+		{"^0.4", "v0.4.2", false},
+		{"^0.4.1 <0.4.2", "v0.4.1", false},
+		{">=0.9 <1.0", "v0.9.5", false},
+		{">= 0.9, < 1.0", "v0.9.5", false},
+		{"=1.0.0", "v1.0", false},
+		{">1.5.2", "v2.0.0", false},
+		{">=1.5.3-rc.1 <2", "v1.5.3-rc.1", false},
+		{"^3", "", false},
+		{"^x", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			if !IsConstraint(tt.constraint) {
+				t.Errorf("IsConstraint(%q) = false", tt.constraint)
+			}
+			c, err := ParseConstraint(tt.constraint)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseConstraint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got, _ := c.MatchTag(tags); got != tt.want {
+				t.Errorf("MatchTag() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	for _, tag := range []string{"v1.2.3", "latest", "nightly+0123456789ab"} {
+		if IsConstraint(tag) {
+			t.Errorf("IsConstraint(%q) = true", tag)
+		}
+	}
+}
+
+func TestConstraintRelease(t *testing.T) {
+	pages := [][]string{{"nightly", "v1.3.0", "v2.0.0"}, {"v1.2.0", "not-a-version", "release-1.2.5"}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/tool/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		page := 0
+		if r.URL.Query().Get("page") == "2" {
+			page = 1
+		} else {
+			w.Header().Set("Link", `<https://api.github.com/repos/owner/tool/releases?page=2>; rel="next"`)
+		}
+		var releases []map[string]any
+		for _, tag := range pages[page] {
+			releases = append(releases, map[string]any{
+				"tag_name": tag,
+				"assets":   []map[string]string{{"name": "tool_linux_amd64", "browser_download_url": "https://github.com/download/" + tag}},
+			})
+		}
+		json.NewEncoder(w).Encode(releases)
+	}))
+	defer server.Close()
+
+	tmp := t.TempDir()
+	gpm := NewGPM(
+		WithStorePath(filepath.Join(tmp, "store")),
+		WithHTTPClient(&http.Client{Transport: serverTransport{server}}),
+		WithHTTPCache(false),
+		WithRetryPolicy(RetryPolicy{Attempts: 1}),
+	)
+	dep := Dependency{Owner: "owner", Repo: "tool", ReleaseTag: "~1.2"}
+	if _, _, err := gpm.ResolveAsset(context.Background(), &dep); err != nil {
+		t.Fatal(err)
+	}
+	if dep.ReleaseTag != "release-1.2.5" {
+		t.Errorf("ResolveAsset() resolved ~1.2 to %q, want release-1.2.5", dep.ReleaseTag)
+	}
+	dep.ReleaseTag = "^3"
+	if _, _, err := gpm.ResolveAsset(context.Background(), &dep); err == nil {
+		t.Errorf("ResolveAsset() resolved ^3 to %q, want an error", dep.ReleaseTag)
+	}
+
+	// Offline, constraints match the highest installed version.
+	for _, tag := range []string{"v1.2.0", "v1.2.3", "v1.3.0"} {
+		if err := os.MkdirAll(filepath.Join(tmp, "store", "github.com", "owner", "tool", tag, "tool_linux_amd64"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cached, err := gpm.FindCachedDependency(context.Background(), Dependency{Repo: "tool", ReleaseTag: "~1.2"}); err != nil || cached.ReleaseTag != "v1.2.3" {
+		t.Errorf("FindCachedDependency(~1.2) = %q, %v, want v1.2.3", cached.ReleaseTag, err)
+	}
+}
@@ -0,0 +1,225 @@
+package gpm
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// shimTargetPrefix starts the line of shim scripts holding the executable they run outside of projects.
+const shimTargetPrefix = "# gpm-shim-target: "
+
+// WithShims makes gpm write launcher scripts into the bin dir instead of symlinks. A shim runs the version
+// of its executable pinned by the manifest and lock file of the project of the current directory, or else
+// the version it was written for. See [GPM.ResolveShim].
+func WithShims(enabled bool) GPMOption {
+	return func(gpm *GPM) {
+		gpm.shims = enabled
+	}
+}
+
+// writeShim writes at shimPath a script running the executable name through gpm, which defaults to filePath.
+// The script passes the store and config directories of gpm to the shim command. Like [link], the script is
+// written under a temporary name then renamed.
+func (gpm GPM) writeShim(shimPath, name, filePath string) error {
+	if err := checkReplaceable(shimPath); err != nil {
+		return err
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get path of gpm: %w", err)
+	}
+	gpmPath, err := gpm.stableSelfPath(executable)
+	if err != nil {
+		return err
+	}
+	storePath, err := gpm.GetStorePath()
+	if err != nil {
+		return fmt.Errorf("failed to get store path: %w", err)
+	}
+	configPath, err := gpm.GetConfigPath()
+	if err != nil {
+		return fmt.Errorf("failed to get config path: %w", err)
+	}
+	script := fmt.Sprintf("#!/bin/sh\n# Generated by gpm, runs the version of %s pinned by the nearest gpm.yaml.\n%s%s\nexec %s %s %s shim %s %s \"$@\"\n",
+		name, shimTargetPrefix, filePath, shellQuote(gpmPath), shellQuote("--store-dir="+storePath), shellQuote("--config-dir="+configPath),
+		shellQuote(name), shellQuote(filePath))
+	tmpPath := filepath.Join(filepath.Dir(shimPath), fmt.Sprintf(".%s.tmp-%d", filepath.Base(shimPath), rand.Int63()))
+	if err := os.WriteFile(tmpPath, []byte(script), 0755); err != nil {
+		return fmt.Errorf("failed to write shim %q: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, shimPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace %q: %w", shimPath, err)
+	}
+	log.Printf("Wrote shim %q -> %q", shimPath, filePath)
+	return nil
+}
+
+// stableSelfPath returns a path running the gpm at executable that outlives its version. When gpm runs from
+// the store, whose entries are removed by gc once replaced by self-update, it is a symlink of a bin dir to
+// it, which self-update points to new versions, or else gpm found in PATH outside of the store.
+func (gpm GPM) stableSelfPath(executable string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(executable)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path of gpm: %w", err)
+	}
+	_, entryPath, ok := gpm.StoreDependency(resolved)
+	if !ok {
+		return executable, nil
+	}
+	storeLinks, err := gpm.storeLinks()
+	if err != nil {
+		return "", err
+	}
+	for _, symLinkPath := range storeLinks[entryPath] {
+		// Shims are skipped, a shim running gpm would run itself.
+		if target, err := os.Readlink(symLinkPath); err == nil && target == resolved {
+			return symLinkPath, nil
+		}
+	}
+	if pathGPM, err := exec.LookPath(SelfRepo); err == nil {
+		if _, _, ok := gpm.StoreDependency(pathGPM); !ok {
+			return pathGPM, nil
+		}
+	}
+	log.Printf("Found no link to %q, shims will fail once this version of gpm is removed from the store", resolved)
+	return resolved, nil
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// readShim returns the executable the shim at shimPath runs outside of projects.
+func readShim(shimPath string) (string, bool) {
+	f, err := os.Open(shimPath)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for i := 0; i < 3 && scanner.Scan(); i++ {
+		if target := strings.TrimPrefix(scanner.Text(), shimTargetPrefix); target != scanner.Text() {
+			return target, true
+		}
+	}
+	return "", false
+}
+
+// readLink returns the target of the symlink or shim at linkPath.
+func readLink(linkPath string) (string, error) {
+	target, err := os.Readlink(linkPath)
+	if err == nil {
+		return target, nil
+	}
+	if target, ok := readShim(linkPath); ok {
+		return target, nil
+	}
+	return "", err
+}
+
+// checkReplaceable fails if a file that is neither a symlink nor a shim exists at linkPath.
+func checkReplaceable(linkPath string) error {
+	fileInfo, err := os.Lstat(linkPath)
+	if err != nil || fileInfo.Mode()&os.ModeSymlink != 0 {
+		return nil
+	}
+	if _, ok := readShim(linkPath); ok {
+		return nil
+	}
+	return fmt.Errorf("%q exists and is neither a symlink nor a gpm shim", linkPath)
+}
+
+// ResolveShim returns the executable the shim of name runs from dir. When the manifest of the project of
+// dir requires the repository of target, the executable of the version it pins, in its lock file or else
+// in the manifest, is returned. It must have been installed. Otherwise, target is returned.
+func (gpm GPM) ResolveShim(ctx context.Context, dir, name, target string) (string, error) {
+	defaultDep, defaultEntry, ok := gpm.StoreDependency(target)
+	if !ok {
+		return target, nil
+	}
+	manifestPath, err := FindManifest(dir, ManifestFileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return target, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	manifest, err := LoadManifest(manifestPath)
+	if err != nil {
+		return "", err
+	}
+	lockFile, err := LoadLockFile(LockFilePath(manifestPath))
+	if err != nil {
+		return "", err
+	}
+	for _, spec := range manifest.Dependencies {
+		deps, err := ConvertDependenciesStrings(spec)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse dependencies of %q: %w", manifestPath, err)
+		}
+		dep := lockFile.Apply(spec, deps[0], CurrentPlatform())
+		if dep.Owner == "" {
+			// Only load registries for the dependencies that need it, and only local ones, shims must start fast.
+			if deps, err = gpm.ConvertLocalDependenciesStrings(spec); err != nil {
+				return "", err
+			}
+			dep = deps[0]
+		}
+		if !strings.EqualFold(dep.Owner, defaultDep.Owner) || !strings.EqualFold(dep.Repo, defaultDep.Repo) {
+			continue
+		}
+		cached, err := gpm.FindCachedDependency(ctx, dep)
+		if err != nil {
+			return "", fmt.Errorf("%s is required by %q, run gpm install: %w", dep, manifestPath, err)
+		}
+		// The executable is usually at the same path in all the assets of a repository.
+		rel, err := filepath.Rel(defaultEntry, target)
+		if err != nil {
+			return "", err
+		}
+		storePath, err := gpm.GetStorePath()
+		if err != nil {
+			return "", fmt.Errorf("failed to get store path: %w", err)
+		}
+		entryPath := filepath.Join(storePath, "github.com", cached.Owner, cached.Repo, cached.ReleaseTag, cached.AssetName)
+		if fileInfo, err := os.Stat(filepath.Join(entryPath, rel)); err == nil && fileInfo.Mode().IsRegular() {
+			return filepath.Join(entryPath, rel), nil
+		}
+		cached.Package = gpm.localPackageOf(cached)
+		if executables, err := FindExecutables(entryPath, cached); err == nil {
+			if filePath, ok := executables[name]; ok {
+				return filePath, nil
+			}
+		}
+		if filePath, ok := findFile(entryPath, filepath.Base(target)); ok {
+			return filePath, nil
+		}
+		return "", fmt.Errorf("no executable named %q in %s required by %q", name, cached, manifestPath)
+	}
+	return target, nil
+}
+
+// findFile returns the first regular file named name in dir or its subdirectories.
+func findFile(dir, name string) (string, bool) {
+	found := ""
+	errFound := errors.New("found")
+	filepath.WalkDir(dir, func(filePath string, dirEntry fs.DirEntry, err error) error {
+		if err == nil && dirEntry.Type().IsRegular() && dirEntry.Name() == name {
+			found = filePath
+			return errFound
+		}
+		return nil
+	})
+	return found, found != ""
+}
@@ -0,0 +1,149 @@
+package gpm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShims(t *testing.T) {
+	tmp := t.TempDir()
+	storePath := filepath.Join(tmp, "store")
+	binPath := filepath.Join(tmp, "bin")
+	executables := map[string]string{}
+	for _, tag := range []string{"v1.0.0", "v2.0.0"} {
+		dir := filepath.Join(storePath, "github.com", "owner", "tool", tag, "tool_linux_amd64", "tool-"+tag)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		executables[tag] = filepath.Join(dir, "tool")
+		if err := os.WriteFile(executables[tag], []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gpm := NewGPM(WithStorePath(storePath), WithBinPath(binPath), WithConfigPath(filepath.Join(tmp, "config")), WithShims(true))
+	ctx := context.Background()
+
+	if err := gpm.linkDependency(ctx, Dependency{Owner: "owner", Repo: "tool", ReleaseTag: "v1.0.0", AssetName: "tool_linux_amd64", Package: &Package{Bin: map[string]string{"tool": "*/tool"}}}); err != nil {
+		t.Fatal(err)
+	}
+	shimPath := filepath.Join(binPath, "tool")
+	if fileInfo, err := os.Lstat(shimPath); err != nil || !fileInfo.Mode().IsRegular() {
+		t.Fatalf("linkDependency() did not write a shim at %q: %v", shimPath, err)
+	}
+	if target, err := readLink(shimPath); err != nil || target != executables["v1.0.0"] {
+		t.Errorf("readLink() = %q, %v, want %q", target, err, executables["v1.0.0"])
+	}
+	if err := link(shimPath, executables["v2.0.0"]); err != nil {
+		t.Errorf("link() failed to replace shim: %v", err)
+	}
+	if err := gpm.writeShim(shimPath, "tool", executables["v1.0.0"]); err != nil {
+		t.Errorf("writeShim() failed to replace symlink: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		manifest string
+		lock     string
+		want     string
+		wantErr  bool
+	}{
+		{"No project", "", "", executables["v1.0.0"], false},
+		{"Other dependencies", "dependencies: [owner/other@v1.0.0]", "", executables["v1.0.0"], false},
+		{"Pinned by manifest", "dependencies: [owner/tool@2.0.0]", "", executables["v2.0.0"], false},
+		{"Pinned by lock", "dependencies: [owner/tool]", "dependencies:\n  owner/tool: {owner: owner, repo: tool, tag: v2.0.0}\n", executables["v2.0.0"], false},
+		{"Not installed", "dependencies: [owner/tool@v3.0.0]", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.manifest != "" {
+				if err := os.WriteFile(filepath.Join(dir, ManifestFileName), []byte(tt.manifest), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.lock != "" {
+				if err := os.WriteFile(filepath.Join(dir, LockFileName), []byte(tt.lock), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := gpm.ResolveShim(ctx, dir, "tool", executables["v1.0.0"])
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveShim() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveShim() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Shims only read local registries.
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	remote := NewGPM(WithStorePath(storePath), WithBinPath(binPath), WithConfigPath(filepath.Join(tmp, "config")),
+		WithRegistries("https://example.com/registry.yaml"), WithHTTPClient(&http.Client{Transport: serverTransport{server}}))
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ManifestFileName), []byte("dependencies: [tool@2.0.0]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := remote.ResolveShim(ctx, dir, "tool", executables["v1.0.0"]); err != nil || requests != 0 {
+		t.Errorf("ResolveShim() error = %v after %d requests, want no request", err, requests)
+	}
+}
+
+func TestStableSelfPath(t *testing.T) {
+	tmp := t.TempDir()
+	storePath := filepath.Join(tmp, "store")
+	binPath := filepath.Join(tmp, "bin")
+	pathDir := filepath.Join(tmp, "usr", "bin")
+	entry := filepath.Join(storePath, "github.com", SelfOwner, SelfRepo, "v1.0.0", SelfAssetName("v1.0.0", CurrentPlatform()))
+	for _, dir := range []string{entry, binPath, pathDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	executable := filepath.Join(entry, SelfRepo)
+	for _, filePath := range []string{executable, filepath.Join(pathDir, SelfRepo), filepath.Join(tmp, SelfRepo)} {
+		if err := os.WriteFile(filePath, []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	linkPath := filepath.Join(binPath, SelfRepo)
+	gpm := NewGPM(WithStorePath(storePath), WithBinPath(binPath), WithConfigPath(filepath.Join(tmp, "config")))
+
+	tests := []struct {
+		name       string
+		executable string
+		link       bool
+		pathEnv    string
+		want       string
+	}{
+		{"Outside of the store", filepath.Join(tmp, SelfRepo), true, "", filepath.Join(tmp, SelfRepo)},
+		{"Bin dir link", executable, true, pathDir, linkPath},
+		{"PATH", executable, false, pathDir, filepath.Join(pathDir, SelfRepo)},
+		{"Store", executable, false, "", executable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PATH", tt.pathEnv)
+			if err := os.Remove(linkPath); err != nil && !os.IsNotExist(err) {
+				t.Fatal(err)
+			}
+			if tt.link {
+				if err := os.Symlink(executable, linkPath); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got, err := gpm.stableSelfPath(tt.executable); err != nil || got != tt.want {
+				t.Errorf("stableSelfPath() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,461 @@
+package gpm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// historySize is the number of previously active targets kept per link.
+const historySize = 10
+
+// GetHistoryPath returns the path of the file recording the previous targets of links, for [GPM.Rollback].
+func (gpm GPM) GetHistoryPath() (string, error) {
+	storePath, err := gpm.GetStorePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(storePath, "history.json"), nil
+}
+
+// loadHistory reads the previous targets of links, by link path, the most recent last.
+func (gpm GPM) loadHistory() (map[string][]string, error) {
+	historyPath, err := gpm.GetHistoryPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history path: %w", err)
+	}
+	history := map[string][]string{}
+	data, err := os.ReadFile(historyPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", historyPath, err)
+	}
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", historyPath, err)
+	}
+	return history, nil
+}
+
+func (gpm GPM) saveHistory(history map[string][]string) error {
+	historyPath, err := gpm.GetHistoryPath()
+	if err != nil {
+		return fmt.Errorf("failed to get history path: %w", err)
+	}
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(historyPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %q: %w", filepath.Dir(historyPath), err)
+	}
+	if err := os.WriteFile(historyPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %q: %w", historyPath, err)
+	}
+	return nil
+}
+
+// linkExecutables links executables, by link name, into the bin dir, with symlinks or shims (see [WithShims]).
+// The store entries the links pointed to before are recorded in the history for [GPM.Rollback]. The
+// previous targets of the replaced links, or "" for new links, are returned by link path for
+// [GPM.restoreLinks].
+func (gpm GPM) linkExecutables(ctx context.Context, executables map[string]string) (map[string]string, error) {
+	binPath, err := gpm.GetBinPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bin path: %w", err)
+	}
+	if err := os.MkdirAll(binPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create bin directory %q: %w", binPath, err)
+	}
+	links := make(map[string]string, len(executables))
+	for name, filePath := range executables {
+		links[filepath.Join(binPath, name)] = filePath
+	}
+	return gpm.replaceLinks(ctx, links)
+}
+
+// replaceLinks points the links of links, by path, to their executables, and records the bin dirs holding
+// them. See [GPM.linkExecutables].
+func (gpm GPM) replaceLinks(ctx context.Context, links map[string]string) (map[string]string, error) {
+	unlock, err := gpm.lockLinks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	history, err := gpm.loadHistory()
+	if err != nil {
+		return nil, err
+	}
+	changed := false
+	replaced := map[string]string{}
+	for _, symLinkPath := range sortedKeys(links) {
+		if err := gpm.recordBinPath(filepath.Dir(symLinkPath)); err != nil {
+			return replaced, err
+		}
+		target, err := readLink(symLinkPath)
+		if err == nil && target == links[symLinkPath] {
+			continue
+		}
+		replaced[symLinkPath] = target
+		if err == nil {
+			if _, _, ok := gpm.StoreDependency(target); ok {
+				previous := append(history[symLinkPath], target)
+				if len(previous) > historySize {
+					previous = previous[len(previous)-historySize:]
+				}
+				history[symLinkPath] = previous
+				changed = true
+			}
+		}
+		if err := gpm.linkExecutable(symLinkPath, links[symLinkPath]); err != nil {
+			return replaced, err
+		}
+	}
+	if changed {
+		return replaced, gpm.saveHistory(history)
+	}
+	return replaced, nil
+}
+
+// linkExecutable links filePath at symLinkPath, with a symlink or a shim (see [WithShims]).
+func (gpm GPM) linkExecutable(symLinkPath, filePath string) error {
+	if gpm.shims {
+		return gpm.writeShim(symLinkPath, filepath.Base(symLinkPath), filePath)
+	}
+	return link(symLinkPath, filePath)
+}
+
+// restoreLinks points the links replaced by [GPM.linkExecutables] back to their previous targets, removing
+// the new ones, and drops the previous targets from the history.
+func (gpm GPM) restoreLinks(ctx context.Context, replaced map[string]string) error {
+	unlock, err := gpm.lockLinks(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	history, err := gpm.loadHistory()
+	if err != nil {
+		return err
+	}
+	for _, symLinkPath := range sortedKeys(replaced) {
+		target := replaced[symLinkPath]
+		if target == "" {
+			if err := os.Remove(symLinkPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to remove %q: %w", symLinkPath, err)
+			}
+			log.Printf("Removed %q", symLinkPath)
+			continue
+		}
+		if err := gpm.linkExecutable(symLinkPath, target); err != nil {
+			return err
+		}
+		if previous := history[symLinkPath]; len(previous) > 0 && previous[len(previous)-1] == target {
+			history[symLinkPath] = previous[:len(previous)-1]
+			if len(history[symLinkPath]) == 0 {
+				delete(history, symLinkPath)
+			}
+		}
+	}
+	return gpm.saveHistory(history)
+}
+
+// StoreDependency returns the dependency whose store entry holds path, and the directory of this entry.
+func (gpm GPM) StoreDependency(path string) (Dependency, string, bool) {
+	storePath, err := gpm.GetStorePath()
+	if err != nil {
+		return Dependency{}, "", false
+	}
+	rel, err := filepath.Rel(storePath, path)
+	if err != nil {
+		return Dependency{}, "", false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) < 5 || parts[0] != "github.com" {
+		return Dependency{}, "", false
+	}
+	dep := Dependency{Owner: parts[1], Repo: parts[2], ReleaseTag: parts[3], AssetName: parts[4]}
+	return dep, filepath.Join(storePath, filepath.FromSlash(strings.Join(parts[:5], "/"))), true
+}
+
+// packageOf returns the package of dep, looking it up by repository in the registries if dep has none.
+func (gpm GPM) packageOf(ctx context.Context, dep Dependency) *Package {
+	if dep.Package != nil {
+		return dep.Package
+	}
+	registries, err := gpm.LoadRegistries(ctx)
+	if err != nil {
+		log.Printf("Failed to load registries: %s", err.Error())
+		return nil
+	}
+	return lookupPackageByRepo(registries, dep.Owner+"/"+dep.Repo)
+}
+
+// localPackageOf is [GPM.packageOf] looking dep up in the registries read without network access only.
+func (gpm GPM) localPackageOf(dep Dependency) *Package {
+	if dep.Package != nil {
+		return dep.Package
+	}
+	registries, err := gpm.LoadLocalRegistries()
+	if err != nil {
+		log.Printf("Failed to load registries: %s", err.Error())
+		return nil
+	}
+	return lookupPackageByRepo(registries, dep.Owner+"/"+dep.Repo)
+}
+
+// FindCachedDependency returns the downloaded dependency matching dep, whose owner and asset name are
+// optional. A release tag without "v" prefix also matches the same tag with it, and no release tag or
+// a channel matches the most recently installed version. A tag without stamp matches its most recently
+// installed stamped tag, like moving tags or tags whose asset changed (see [StampTag]), and a version
+// constraint the highest installed version satisfying it (see [ParseConstraint]). When several assets of the release were downloaded, the one matching
+// the current platform is picked.
+func (gpm GPM) FindCachedDependency(ctx context.Context, dep Dependency) (Dependency, error) {
+	downloaded, err := gpm.ListDownloadedDependencies(ctx)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Dependency{}, err
+	}
+	latest := IsChannel(dep.ReleaseTag)
+	_, stamp := SplitStampedTag(dep.ReleaseTag)
+	unstamped := stamp == ""
+	var constraint *Constraint
+	if IsConstraint(dep.ReleaseTag) {
+		c, err := ParseConstraint(dep.ReleaseTag)
+		if err != nil {
+			return Dependency{}, err
+		}
+		constraint = &c
+	}
+	var candidates []Dependency
+	for _, tag := range []string{dep.ReleaseTag, "v" + dep.ReleaseTag} {
+		for _, cached := range downloaded {
+			cachedTag := cached.ReleaseTag
+			if unstamped {
+				cachedTag, _ = SplitStampedTag(cachedTag)
+			}
+			matchesTag := latest || cachedTag == tag
+			if constraint != nil {
+				version, ok := ParseVersion(cachedTag)
+				matchesTag = ok && constraint.Match(version)
+			}
+			if strings.EqualFold(cached.Repo, dep.Repo) && matchesTag &&
+				(dep.Owner == "" || strings.EqualFold(cached.Owner, dep.Owner)) &&
+				(dep.AssetName == "" || cached.AssetName == dep.AssetName) {
+				candidates = append(candidates, cached)
+			}
+		}
+		if len(candidates) > 0 || latest || constraint != nil || strings.HasPrefix(dep.ReleaseTag, "v") {
+			break
+		}
+	}
+	if len(candidates) == 0 {
+		return Dependency{}, fmt.Errorf("%q is not downloaded: %w", dep, ErrNotCached)
+	}
+	if constraint != nil {
+		tags := make([]string, 0, len(candidates))
+		for _, candidate := range candidates {
+			tags = append(tags, candidate.ReleaseTag)
+		}
+		highest, _ := constraint.MatchTag(tags)
+		highest, _ = SplitStampedTag(highest)
+		var matching []Dependency
+		for _, candidate := range candidates {
+			if tag, _ := SplitStampedTag(candidate.ReleaseTag); tag == highest {
+				matching = append(matching, candidate)
+			}
+		}
+		candidates = matching
+	}
+	owners := map[string]bool{}
+	for _, candidate := range candidates {
+		owners[candidate.Owner] = true
+	}
+	if len(owners) > 1 {
+		return Dependency{}, fmt.Errorf("%w: %q could be any of %s", ErrAmbiguousOwner, dep, strings.Join(sortedKeys(owners), ", "))
+	}
+	if latest || unstamped {
+		candidates = gpm.latestCached(candidates)
+	}
+	found := candidates[0]
+	if len(candidates) > 1 {
+		names := make([]string, 0, len(candidates))
+		for _, candidate := range candidates {
+			names = append(names, candidate.AssetName)
+		}
+		if platformNames := PlatformAssets(names, CurrentPlatform()); len(platformNames) > 0 {
+			found.AssetName = platformNames[0]
+		}
+	}
+	found.Package = dep.Package
+	return found, nil
+}
+
+// latestCached returns the deps of the most recently installed release tag among deps.
+func (gpm GPM) latestCached(deps []Dependency) []Dependency {
+	storePath, err := gpm.GetStorePath()
+	if err != nil {
+		return deps
+	}
+	var latestTag string
+	var latestTime time.Time
+	for _, dep := range deps {
+		fileInfo, err := os.Stat(filepath.Join(storePath, "github.com", dep.Owner, dep.Repo, dep.ReleaseTag, dep.AssetName))
+		if err == nil && fileInfo.ModTime().After(latestTime) {
+			latestTag, latestTime = dep.ReleaseTag, fileInfo.ModTime()
+		}
+	}
+	var latest []Dependency
+	for _, dep := range deps {
+		if dep.ReleaseTag == latestTag {
+			latest = append(latest, dep)
+		}
+	}
+	if len(latest) == 0 {
+		return deps
+	}
+	return latest
+}
+
+// Use links the executables of an already downloaded version of dep, without network access: the
+// package of dep is only looked up in local registries. See [GPM.FindCachedDependency] for how dep is
+// matched.
+func (gpm GPM) Use(ctx context.Context, dep Dependency) (Dependency, error) {
+	cached, err := gpm.FindCachedDependency(ctx, dep)
+	if err != nil {
+		return Dependency{}, err
+	}
+	cached.Package = gpm.localPackageOf(cached)
+	return cached, gpm.linkDependency(ctx, cached)
+}
+
+// linkDependency links the executables of the store entry of dep, holding its entry lock so that
+// [GPM.GC] does not remove it meanwhile.
+func (gpm GPM) linkDependency(ctx context.Context, dep Dependency) error {
+	unlock, err := gpm.lockAsset(ctx, dep)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	executables, err := gpm.entryExecutables(dep)
+	if err != nil {
+		return err
+	}
+	_, err = gpm.linkExecutables(ctx, executables)
+	return err
+}
+
+// entryExecutables returns the executables of the store entry of dep, by link name.
+func (gpm GPM) entryExecutables(dep Dependency) (map[string]string, error) {
+	storePath, err := gpm.GetStorePath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gpm store path: %w", err)
+	}
+	return FindExecutables(filepath.Join(storePath, "github.com", dep.Owner, dep.Repo, dep.ReleaseTag, dep.AssetName), dep)
+}
+
+// Rollback links the executables of the version that the link name in the bin dir pointed to before
+// its last change. Rolling back twice returns to the current version.
+func (gpm GPM) Rollback(ctx context.Context, name string) (Dependency, error) {
+	binPath, err := gpm.GetBinPath()
+	if err != nil {
+		return Dependency{}, fmt.Errorf("failed to get bin path: %w", err)
+	}
+	symLinkPath := filepath.Join(binPath, name)
+	history, err := gpm.loadHistory()
+	if err != nil {
+		return Dependency{}, err
+	}
+	previous := history[symLinkPath]
+	for i := len(previous) - 1; i >= 0; i-- {
+		target := previous[i]
+		dep, dir, ok := gpm.StoreDependency(target)
+		if !ok {
+			continue
+		}
+		if _, err := os.Stat(dir); err != nil {
+			log.Printf("Skipping %q in history of %q: %s", target, symLinkPath, err.Error())
+			continue
+		}
+		current, _ := readLink(symLinkPath)
+		dep.Package = gpm.localPackageOf(dep)
+		if err := gpm.linkDependency(ctx, dep); err != nil {
+			return Dependency{}, err
+		}
+		// The history is only changed once target is linked, so that a failed rollback can be retried.
+		return dep, gpm.dropHistory(ctx, symLinkPath, target, current)
+	}
+	return Dependency{}, fmt.Errorf("no previous version of %q to roll back to", symLinkPath)
+}
+
+// dropHistory removes target, which symLinkPath was rolled back to, from the history of symLinkPath along
+// with the versions recorded after it, except replaced, the target symLinkPath pointed to before, which
+// linking target recorded last.
+func (gpm GPM) dropHistory(ctx context.Context, symLinkPath, target, replaced string) error {
+	unlock, err := gpm.lockLinks(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	history, err := gpm.loadHistory()
+	if err != nil {
+		return err
+	}
+	previous := history[symLinkPath]
+	var recorded []string
+	if len(previous) > 0 && replaced != "" && replaced != target && previous[len(previous)-1] == replaced {
+		previous, recorded = previous[:len(previous)-1], previous[len(previous)-1:]
+	}
+	for i := len(previous) - 1; i >= 0; i-- {
+		if previous[i] == target {
+			history[symLinkPath] = append(previous[:i:i], recorded...)
+			if len(history[symLinkPath]) == 0 {
+				delete(history, symLinkPath)
+			}
+			return gpm.saveHistory(history)
+		}
+	}
+	return nil
+}
+
+// Version is a release tag of a repository, downloaded in the store or published on Github.
+type Version struct {
+	Tag    string `json:"tag"`
+	Cached bool   `json:"cached"`
+	Remote bool   `json:"remote"`
+	// Active is set when executables of the bin dir point to this version.
+	Active bool `json:"active"`
+}
+
+// CachedVersions returns the versions of owner/repo downloaded in the store, sorted by tag.
+func (gpm GPM) CachedVersions(ctx context.Context, owner, repo string) ([]Version, error) {
+	downloaded, err := gpm.ListDownloadedDependencies(ctx)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	active := map[string]bool{}
+	if links, err := gpm.ListLinkedDependencies(ctx); err == nil {
+		for _, linked := range links {
+			if dep, _, ok := gpm.StoreDependency(linked.Dst); ok && strings.EqualFold(dep.Owner, owner) && strings.EqualFold(dep.Repo, repo) {
+				active[dep.ReleaseTag] = true
+			}
+		}
+	}
+	seen := map[string]bool{}
+	var versions []Version
+	for _, dep := range downloaded {
+		if !strings.EqualFold(dep.Owner, owner) || !strings.EqualFold(dep.Repo, repo) || seen[dep.ReleaseTag] {
+			continue
+		}
+		seen[dep.ReleaseTag] = true
+		versions = append(versions, Version{Tag: dep.ReleaseTag, Cached: true, Active: active[dep.ReleaseTag]})
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Tag < versions[j].Tag })
+	return versions, nil
+}
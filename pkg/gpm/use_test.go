@@ -0,0 +1,78 @@
+package gpm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUseAndRollback(t *testing.T) {
+	tmp := t.TempDir()
+	gpm := NewGPM(WithStorePath(filepath.Join(tmp, "store")), WithBinPath(filepath.Join(tmp, "bin")))
+	ctx := context.Background()
+	for _, tag := range []string{"v1.0.0", "v2.0.0"} {
+		dir := filepath.Join(tmp, "store", "github.com", "owner", "tool", tag, "tool_linux_amd64")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "tool"), []byte(tag), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	linked := func() string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(tmp, "bin", "tool"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	if _, err := gpm.Use(ctx, Dependency{Repo: "tool", ReleaseTag: "1.0.0"}); err != nil {
+		t.Fatalf("Use() error = %v", err)
+	}
+	if got := linked(); got != "v1.0.0" {
+		t.Errorf("Use(1.0.0) linked %q", got)
+	}
+	if _, err := gpm.Use(ctx, Dependency{Owner: "owner", Repo: "tool", ReleaseTag: "v2.0.0"}); err != nil {
+		t.Fatalf("Use() error = %v", err)
+	}
+	if got := linked(); got != "v2.0.0" {
+		t.Errorf("Use(v2.0.0) linked %q", got)
+	}
+	if _, err := gpm.Use(ctx, Dependency{Repo: "tool", ReleaseTag: "v3.0.0"}); err == nil {
+		t.Error("Use(v3.0.0) succeeded, want error for a version not downloaded")
+	}
+
+	versions, err := gpm.CachedVersions(ctx, "owner", "tool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Version{{Tag: "v1.0.0", Cached: true}, {Tag: "v2.0.0", Cached: true, Active: true}}
+	if !reflect.DeepEqual(versions, want) {
+		t.Errorf("CachedVersions() = %+v, want %+v", versions, want)
+	}
+
+	// A rollback that fails to link keeps the previous version in the history.
+	unlock, err := gpm.lockAsset(ctx, Dependency{Owner: "owner", Repo: "tool", ReleaseTag: "v1.0.0", AssetName: "tool_linux_amd64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := gpm.Rollback(canceled, "tool"); err == nil {
+		t.Error("Rollback() succeeded while the previous version is locked")
+	}
+	unlock()
+
+	for _, want := range []string{"v1.0.0", "v2.0.0"} {
+		if _, err := gpm.Rollback(ctx, "tool"); err != nil {
+			t.Fatalf("Rollback() error = %v", err)
+		}
+		if got := linked(); got != want {
+			t.Errorf("Rollback() linked %q, want %q", got, want)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/ctison/gpm/pkg/gpm"
 )
 
 type DashboardModel struct {
@@ -16,10 +17,10 @@ type DashboardModel struct {
 	windowSize tea.WindowSizeMsg
 }
 
-func NewDashboardModel() *DashboardModel {
+func NewDashboardModel(gpm gpm.GPM) *DashboardModel {
 	dm := &DashboardModel{}
-	dm.AddTab("F1 Search", NewSearch())
-	dm.AddTab("F2 Installed", NewSearch())
+	dm.AddTab("F1 Search", NewSearch(gpm))
+	dm.AddTab("F2 Installed", NewSearch(gpm))
 	return dm
 }
 
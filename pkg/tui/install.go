@@ -2,7 +2,9 @@ package tui
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
@@ -11,6 +13,9 @@ import (
 )
 
 type InstallModel struct {
+	ctx           context.Context
+	cancel        context.CancelFunc
+	canceled      bool
 	width, height int
 	deps          []gpm.Dependency
 	spinners      []spinner.Model
@@ -20,8 +25,12 @@ type InstallModel struct {
 	totalDone     int
 }
 
-func NewInstallModel(gpm gpm.GPM, deps ...gpm.Dependency) InstallModel {
+// NewInstallModel returns a model installing deps concurrently. Installs are canceled with ctx or Ctrl+C.
+func NewInstallModel(ctx context.Context, gpm gpm.GPM, deps ...gpm.Dependency) InstallModel {
+	ctx, cancel := context.WithCancel(ctx)
 	im := InstallModel{
+		ctx:        ctx,
+		cancel:     cancel,
 		deps:       deps,
 		spinners:   make([]spinner.Model, 0, len(deps)),
 		progresses: make([]DownloadProgress, 0, len(deps)),
@@ -32,7 +41,7 @@ func NewInstallModel(gpm gpm.GPM, deps ...gpm.Dependency) InstallModel {
 		im.spinners = append(im.spinners, spinner.New(
 			spinner.WithSpinner(spinner.Dot),
 		))
-		im.progresses = append(im.progresses, NewDownloadProgress(gpm, dep))
+		im.progresses = append(im.progresses, NewDownloadProgress(ctx, gpm, dep))
 	}
 	return im
 }
@@ -46,8 +55,36 @@ func (im InstallModel) Errored() bool {
 	return false
 }
 
+// Installed returns the dependencies resolved by successful installs, by index of the installed
+// dependencies. Dependencies that failed to install are left empty.
+func (im InstallModel) Installed() []gpm.Dependency {
+	installed := make([]gpm.Dependency, len(im.progresses))
+	for i, progress := range im.progresses {
+		installed[i], _ = progress.Installed()
+	}
+	return installed
+}
+
+// Canceled reports whether the installs were canceled before all of them finished.
+func (im InstallModel) Canceled() bool { return im.canceled }
+
+// Wait cancels the installs still running and blocks until they have returned and cleaned up.
+// It must be called once the program has exited.
+func (im InstallModel) Wait() {
+	im.cancel()
+	for _, progress := range im.progresses {
+		progress.Wait()
+	}
+}
+
+type canceledMsg struct{}
+
 func (im InstallModel) Init() tea.Cmd {
-	cmds := make([]tea.Cmd, 0, len(im.spinners)+len(im.progresses))
+	cmds := make([]tea.Cmd, 0, len(im.spinners)+len(im.progresses)+1)
+	cmds = append(cmds, func() tea.Msg {
+		<-im.ctx.Done()
+		return canceledMsg{}
+	})
 	for _, spinner := range im.spinners {
 		cmds = append(cmds, spinner.Tick)
 	}
@@ -65,8 +102,16 @@ func (im InstallModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.Type {
 		case tea.KeyCtrlC:
+			im.cancel()
+			im.canceled = true
 			return im, tea.Quit
 		}
+	case canceledMsg:
+		if im.totalDone < len(im.done) {
+			im.canceled = true
+			return im, tea.Quit
+		}
+		return im, nil
 	}
 	for i := range im.spinners {
 		var cmd tea.Cmd
@@ -100,6 +145,21 @@ var (
 
 func (im InstallModel) View() string {
 	var buf bytes.Buffer
+	if len(im.deps) > 1 {
+		var queued, running, failed int
+		for i, progress := range im.progresses {
+			switch {
+			case im.errors[i] != nil:
+				failed++
+			case im.done[i]:
+			case progress.State() == gpm.StateQueued:
+				queued++
+			default:
+				running++
+			}
+		}
+		buf.WriteString(fmt.Sprintf("%d queued, %d running, %d done, %d failed\n", queued, running, im.totalDone-failed, failed))
+	}
 	for i, dep := range im.deps {
 		if im.errors[i] != nil {
 			buf.WriteString(errorCross.String())
@@ -113,6 +173,11 @@ func (im InstallModel) View() string {
 			buf.WriteString("   " + im.progresses[i].View())
 		}
 		buf.WriteString(fmt.Sprintln(""))
+		if im.errors[i] == nil {
+			for _, hook := range im.progresses[i].Hooks() {
+				buf.WriteString(fmt.Sprintf("    $ %s: %s\n", hook.Command, firstLine(hook.Output)))
+			}
+		}
 	}
 	for _, progress := range im.progresses {
 		if err := progress.Err(); err != nil {
@@ -121,3 +186,13 @@ func (im InstallModel) View() string {
 	}
 	return buf.String()
 }
+
+// firstLine returns the first non-empty line of output.
+func firstLine(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return "(no output)"
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"log"
 	"sync"
+	"time"
 
 	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
@@ -28,6 +29,7 @@ func nextID() int {
 }
 
 type DownloadProgress struct {
+	ctx             context.Context
 	gpm             gpm.GPM
 	id              int
 	reader          io.Reader
@@ -36,38 +38,87 @@ type DownloadProgress struct {
 	c               chan ProgressMsg
 	totalByteSize   int64
 	currentByteSize int64
+	resumedByteSize int64
+	retry           *gpm.RetryEvent
+	lock            *gpm.LockEvent
+	state           gpm.InstallState
+	hooks           []gpm.HookResult
 	err             error
 	finished        bool
+	installed       *gpm.Dependency
+	stopped         chan struct{}
 }
 
-func NewDownloadProgress(gpm gpm.GPM, dep gpm.Dependency, opts ...progress.Option) DownloadProgress {
+// NewDownloadProgress returns a model installing dep when initialized. The install is canceled with ctx.
+func NewDownloadProgress(ctx context.Context, gpm gpm.GPM, dep gpm.Dependency, opts ...progress.Option) DownloadProgress {
 	return DownloadProgress{
+		ctx:      ctx,
 		gpm:      gpm,
 		id:       nextID(),
 		dep:      dep,
 		Progress: progress.New(opts...),
 		c:        make(chan ProgressMsg),
+		stopped:  make(chan struct{}),
 	}
 }
 
 func (dp DownloadProgress) Init() tea.Cmd {
 	go func(dp DownloadProgress) {
-		if err := dp.gpm.InstallDependency(context.Background(), dp.dep, dp); err != nil {
-			dp.c <- ProgressMsg{
+		defer close(dp.stopped)
+		ctx := gpm.WithRetryNotifier(dp.ctx, func(event gpm.RetryEvent) {
+			dp.send(ProgressMsg{
+				id:    dp.id,
+				retry: &event,
+			})
+		})
+		ctx = gpm.WithLockNotifier(ctx, func(event gpm.LockEvent) {
+			dp.send(ProgressMsg{
+				id:   dp.id,
+				lock: &event,
+			})
+		})
+		ctx = gpm.WithStateNotifier(ctx, func(state gpm.InstallState) {
+			dp.send(ProgressMsg{
+				id:    dp.id,
+				state: &state,
+			})
+		})
+		ctx = gpm.WithHookNotifier(ctx, func(result gpm.HookResult) {
+			dp.send(ProgressMsg{
+				id:   dp.id,
+				hook: &result,
+			})
+		})
+		if installed, err := dp.gpm.InstallDependency(ctx, dp.dep, dp); err != nil {
+			dp.send(ProgressMsg{
 				id:  dp.id,
 				err: err,
-			}
+			})
 		} else {
-			dp.c <- ProgressMsg{
-				id:  dp.id,
-				eof: true,
-			}
+			dp.send(ProgressMsg{
+				id:        dp.id,
+				eof:       true,
+				installed: &installed,
+			})
 		}
 		close(dp.c)
 	}(dp)
 	return dp.ListenProgress
 }
 
+// send sends msg to the model, unless the install is canceled and nobody listens anymore.
+func (dp DownloadProgress) send(msg ProgressMsg) {
+	select {
+	case dp.c <- msg:
+	case <-dp.ctx.Done():
+	}
+}
+
+// Wait blocks until the install started by Init returns.
+func (dp DownloadProgress) Wait() {
+	<-dp.stopped
+}
+
 func (dp DownloadProgress) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if prg, ok := msg.(ProgressMsg); ok {
 		if dp.id != prg.id {
@@ -78,15 +129,35 @@ func (dp DownloadProgress) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			dp.finished = true
 			return dp, nil
 		}
+		if prg.lock != nil {
+			dp.lock = prg.lock
+			return dp, dp.ListenProgress
+		}
+		dp.lock = nil
+		if prg.state != nil {
+			dp.state = *prg.state
+			return dp, dp.ListenProgress
+		}
+		if prg.hook != nil {
+			dp.hooks = append(dp.hooks, *prg.hook)
+			return dp, dp.ListenProgress
+		}
+		if prg.retry != nil {
+			dp.retry = prg.retry
+			return dp, dp.ListenProgress
+		}
+		dp.retry = nil
 		if prg.eof {
 			if dp.currentByteSize != dp.totalByteSize {
 				log.Printf("ERROR: %d != %d but eof == true\n", dp.currentByteSize, dp.totalByteSize)
 			}
 			dp.finished = true
+			dp.installed = prg.installed
 			return dp, dp.Progress.SetPercent(100.)
 		}
 		if prg.currentSize != nil {
 			dp.currentByteSize = *prg.currentSize
+			dp.resumedByteSize = *prg.currentSize
 		}
 		if prg.totalSize != nil {
 			dp.totalByteSize = *prg.totalSize
@@ -105,33 +176,67 @@ func (dp DownloadProgress) View() string {
 	if dp.err != nil {
 		return fmt.Sprintf("Error: %s", dp.err.Error())
 	}
+	if dp.retry != nil {
+		if remaining := time.Until(dp.retry.Until); remaining > 0 {
+			return fmt.Sprintf("Retrying in %s (attempt %d failed: %s)", remaining.Round(time.Second), dp.retry.Attempt, dp.retry.Reason)
+		}
+	}
+	if dp.lock != nil {
+		return dp.lock.String()
+	}
+	switch dp.state {
+	case gpm.StateQueued, gpm.StateResolving, gpm.StateInstalling, gpm.StateTesting:
+		return dp.state.String()
+	}
 	if dp.totalByteSize == 0 {
 		return dp.Progress.ViewAs(0.)
 	}
-	return dp.Progress.ViewAs(float64(dp.currentByteSize) / (float64(dp.totalByteSize) / 100) / 100)
+	view := dp.Progress.ViewAs(float64(dp.currentByteSize) / (float64(dp.totalByteSize) / 100) / 100)
+	if dp.resumedByteSize > 0 {
+		view += fmt.Sprintf(" (resumed at %s)", ByteCountIEC(dp.resumedByteSize))
+	}
+	return view
 }
 
 func (dp DownloadProgress) Err() error { return dp.err }
 
 func (dp DownloadProgress) Finished() bool { return dp.finished }
 
+func (dp DownloadProgress) State() gpm.InstallState { return dp.state }
+
+// Hooks returns the results of the smoke test and post-install hooks run so far.
+func (dp DownloadProgress) Hooks() []gpm.HookResult { return dp.hooks }
+
+// Installed returns the dependency resolved by a successful install.
+func (dp DownloadProgress) Installed() (gpm.Dependency, bool) {
+	if dp.installed == nil {
+		return gpm.Dependency{}, false
+	}
+	return *dp.installed, true
+}
+
 type ProgressMsg struct {
 	id                     int
 	src                    *string
 	currentSize, totalSize *int64
 	readSize               *int64
+	retry                  *gpm.RetryEvent
+	lock                   *gpm.LockEvent
+	state                  *gpm.InstallState
+	hook                   *gpm.HookResult
 	err                    error
 	eof                    bool
+	installed              *gpm.Dependency
 }
 
 func (dp DownloadProgress) TrackProgress(src string, currentSize, totalSize int64, stream io.ReadCloser) io.ReadCloser {
 	log.Printf("TrackProgress: %s %d %d", src, currentSize, totalSize)
-	dp.c <- ProgressMsg{
+	dp.send(ProgressMsg{
 		id:          dp.id,
 		src:         &src,
 		currentSize: &currentSize,
 		totalSize:   &totalSize,
-	}
+	})
 	dp.reader = stream
 	return dp
 }
@@ -147,10 +252,10 @@ func (dp DownloadProgress) ListenProgress() tea.Msg {
 func (dp DownloadProgress) Read(p []byte) (n int, err error) {
 	n, err = dp.reader.Read(p)
 	nn := int64(n)
-	dp.c <- ProgressMsg{
+	dp.send(ProgressMsg{
 		id:       dp.id,
 		readSize: &nn,
-	}
+	})
 	return n, err
 }
 
@@ -10,6 +10,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/ctison/gpm/pkg/gpm"
 	"github.com/google/go-github/v47/github"
 )
 
@@ -33,6 +34,7 @@ const (
 )
 
 type SearchModel struct {
+	gpm        gpm.GPM
 	windowSize tea.WindowSizeMsg
 	focused    bool
 	focus      focus
@@ -50,8 +52,9 @@ type SearchModel struct {
 	}
 }
 
-func NewSearch() *SearchModel {
+func NewSearch(gpm gpm.GPM) *SearchModel {
 	search := &SearchModel{
+		gpm:   gpm,
 		focus: focusSearchQuery,
 	}
 	search.views.searchQuery = textinput.New()
@@ -102,7 +105,7 @@ func (this SearchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if this.focus == focusSearchQuery && this.views.searchQuery.Value() != "" {
 				cmd := this.SetView(focusContent, viewFetchingRepositories)
 				return this, tea.Batch(
-					queryRepositories(this.views.searchQuery.Value()),
+					queryRepositories(this.gpm, this.views.searchQuery.Value()),
 					cmd,
 				)
 			}
@@ -110,7 +113,7 @@ func (this SearchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				cmd := this.SetView(focusContent, viewFetchingReleases)
 				selectedRepository := this.data.repositories[this.views.repositories.Cursor()]
 				return this, tea.Batch(
-					queryReleases(selectedRepository.GetOwner().GetLogin(), selectedRepository.GetName()),
+					queryReleases(this.gpm, selectedRepository.GetOwner().GetLogin(), selectedRepository.GetName()),
 					cmd,
 				)
 			}
@@ -336,24 +339,22 @@ func (this SearchModel) RenderSearchInput() string {
 	return searchInput
 }
 
-func queryRepositories(query string) tea.Cmd {
+func queryRepositories(gpm gpm.GPM, query string) tea.Cmd {
 	return func() tea.Msg {
-		client := github.NewClient(nil)
-		result, _, err := client.Search.Repositories(context.Background(), query, &github.SearchOptions{})
+		repositories, err := gpm.SearchRepositories(context.Background(), query)
 		if err != nil {
 			return err
 		}
-		return result.Repositories
+		return repositories
 	}
 }
 
-func queryReleases(owner, repo string) tea.Cmd {
+func queryReleases(gpm gpm.GPM, owner, repo string) tea.Cmd {
 	return func() tea.Msg {
-		client := github.NewClient(nil)
-		result, _, err := client.Repositories.ListReleases(context.Background(), owner, repo, &github.ListOptions{})
+		releases, err := gpm.ListReleases(context.Background(), owner, repo)
 		if err != nil {
 			return err
 		}
-		return result
+		return releases
 	}
 }